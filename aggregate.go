@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type aggregator struct {
+	message, field string
+	window         time.Duration
+	values         []float64
+	first          Entry
+}
+
+type aggregators struct {
+	mu sync.Mutex
+	m  map[string]*aggregator
+}
+
+func (l *Logger) Aggregate(message, field string, window time.Duration) {
+	l.mu.Lock()
+	if l.aggs == nil {
+		l.aggs = &aggregators{m: map[string]*aggregator{}}
+	}
+	aggs := l.aggs
+	l.mu.Unlock()
+	aggs.mu.Lock()
+	defer aggs.mu.Unlock()
+	if window <= 0 {
+		delete(aggs.m, message)
+		return
+	}
+	aggs.m[message] = &aggregator{message: message, field: field, window: window}
+}
+
+func (l *Logger) aggregated(e *Entry) bool {
+	l.mu.Lock()
+	aggs := l.aggs
+	l.mu.Unlock()
+	if aggs == nil {
+		return false
+	}
+	aggs.mu.Lock()
+	defer aggs.mu.Unlock()
+	a, OK := aggs.m[strings.TrimSuffix(e.Message, "\n")]
+	if !OK {
+		return false
+	}
+	v, OK := fieldNumber(e.Fields, a.field)
+	if !OK {
+		return false
+	}
+	if len(a.values) == 0 {
+		a.first = *e
+		time.AfterFunc(a.window, func() { l.flushAggregate(aggs, a) })
+	}
+	a.values = append(a.values, v)
+	return true
+}
+
+func (l *Logger) flushAggregate(aggs *aggregators, a *aggregator) {
+	aggs.mu.Lock()
+	values, first := a.values, a.first
+	a.values = nil
+	aggs.mu.Unlock()
+	if len(values) == 0 {
+		return
+	}
+	sort.Float64s(values)
+	first.Time = now()
+	first.Fields = append(dropField(first.Fields, a.field),
+		Field{Key: "field", Value: a.field},
+		Field{Key: "count", Value: len(values)},
+		Field{Key: "min", Value: values[0]},
+		Field{Key: "max", Value: values[len(values)-1]},
+		Field{Key: "p95", Value: percentile(values, 0.95)},
+	)
+	_ = l.emit(&first)
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func dropField(fields []Field, key string) []Field {
+	res := make([]Field, 0, len(fields)+5)
+	for _, f := range fields {
+		if f.Key != key {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func fieldNumber(fields []Field, key string) (float64, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key == key {
+			return toFloat(fields[i].Value)
+		}
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case time.Duration:
+		return float64(n), true
+	case humanValue:
+		return toFloat(n.raw())
+	}
+	return 0, false
+}
@@ -0,0 +1,183 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFlushInterval = time.Second
+	exitFlushTimeout     = 2 * time.Second
+)
+
+type flusher interface {
+	Flush() error
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []io.Writer
+)
+
+func register(w io.Writer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, w)
+}
+
+func registered() []io.Writer {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return append([]io.Writer(nil), registry...)
+}
+
+func Flush() error {
+	var err error
+	for _, w := range registered() {
+		if f, OK := w.(flusher); OK {
+			if e := f.Flush(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// flushBeforeExit gives buffered outs a bounded chance to reach their files
+// before Fatal exits or Panic unwinds.
+func flushBeforeExit() {
+	done := make(chan struct{})
+	go func() {
+		_ = Flush()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(exitFlushTimeout):
+	}
+}
+
+func FlushEvery(ctx context.Context, interval time.Duration) <-chan error {
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+	done := make(chan error, 1)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = Flush()
+			case <-ctx.Done():
+				done <- Flush()
+				close(done)
+				return
+			}
+		}
+	}()
+	return done
+}
+
+func FlushOn(done <-chan struct{}) <-chan error {
+	flushed := make(chan error, 1)
+	go func() {
+		<-done
+		flushed <- Flush()
+		close(flushed)
+	}()
+	return flushed
+}
+
+func Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		for _, w := range registered() {
+			if c, OK := w.(io.Closer); OK {
+				if e := c.Close(); e != nil && err == nil {
+					err = e
+				}
+			} else if f, OK := w.(flusher); OK {
+				if e := f.Flush(); e != nil && err == nil {
+					err = e
+				}
+			}
+		}
+		if e := waitCompress(ctx); e != nil && err == nil {
+			err = e
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type bufferedWriter struct {
+	mu   sync.Mutex
+	out  io.Writer
+	buf  *bufio.Writer
+	stop chan struct{}
+	once sync.Once
+}
+
+func newBufferedWriter(out io.Writer, size int, interval time.Duration) *bufferedWriter {
+	b := &bufferedWriter{out: out, buf: bufio.NewWriterSize(out, size), stop: make(chan struct{})}
+	go b.loop(interval)
+	return b
+}
+
+func (b *bufferedWriter) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = b.Flush()
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n, err := b.buf.Write(p)
+	return n, b.recover(err)
+}
+
+func (b *bufferedWriter) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.recover(b.buf.Flush())
+}
+
+// recover resets the bufio.Writer after a failed flush. Its errors are
+// sticky, so without a reset one transient failure would kill the out; the
+// buffered entries are lost and reported.
+func (b *bufferedWriter) recover(err error) error {
+	if err != nil {
+		reportError("buffered write failed, %d bytes dropped: %s", b.buf.Buffered(), err)
+		b.buf.Reset(b.out)
+	}
+	return err
+}
+
+func (b *bufferedWriter) Close() error {
+	b.once.Do(func() { close(b.stop) })
+	err := b.Flush()
+	if c, OK := b.out.(io.Closer); OK {
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+var (
+	buildOnce   sync.Once
+	buildFields []Field
+)
+
+func buildInfoFields() []Field {
+	buildOnce.Do(func() {
+		info, OK := debug.ReadBuildInfo()
+		if !OK {
+			return
+		}
+		if v := info.Main.Version; v != "" && v != "(devel)" {
+			buildFields = append(buildFields, Field{Key: "version", Value: v})
+		}
+		var commit, modified, at string
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				commit = s.Value
+			case "vcs.modified":
+				modified = s.Value
+			case "vcs.time":
+				at = s.Value
+			}
+		}
+		if commit != "" {
+			if modified == "true" {
+				commit += "-dirty"
+			}
+			buildFields = append(buildFields, Field{Key: "commit", Value: commit})
+		}
+		if at != "" {
+			buildFields = append(buildFields, Field{Key: "build_time", Value: at})
+		}
+	})
+	return buildFields
+}
+
+func (l *Logger) SetBuildInfo(on bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buildInfo = on
+}
+
+func (m *Loggers) SetBuildInfo(on bool) {
+	m.each(func(l *Logger) { l.SetBuildInfo(on) })
+}
+
+func (l *Logger) stampBuildInfo(e *Entry) {
+	l.mu.Lock()
+	on := l.buildInfo
+	l.mu.Unlock()
+	if fields := buildInfoFields(); on && len(fields) > 0 {
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], fields...)
+	}
+}
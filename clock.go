@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+var SystemClock Clock = ClockFunc(time.Now)
+
+type clockHolder struct {
+	Clock
+}
+
+var entryClock, rotationClock atomic.Value // clockHolder
+
+// SetClock sets the source of entry timestamps. It does not affect when
+// files rotate, see SetRotationClock.
+func SetClock(c Clock) {
+	if c == nil {
+		c = SystemClock
+	}
+	entryClock.Store(clockHolder{c})
+}
+
+// SetRotationClock sets the clock rotating writers use to pick the file
+// suffix, unless a writer was given its own with WithClock.
+func SetRotationClock(c Clock) {
+	if c == nil {
+		c = SystemClock
+	}
+	rotationClock.Store(clockHolder{c})
+}
+
+func now() time.Time {
+	if h, OK := entryClock.Load().(clockHolder); OK {
+		return h.Now()
+	}
+	return time.Now()
+}
+
+func rotationNow() time.Time {
+	if h, OK := rotationClock.Load().(clockHolder); OK {
+		return h.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,45 @@
+// Command logdict trains a deflate preset dictionary from existing log files,
+// for use with network outs configured as ?compress=deflate&dict=<file>.
+// With -decode it turns a captured frame stream on stdin back into entries.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/basebytes/logger"
+)
+
+func main() {
+	size := flag.Int("size", logger.DefaultDictSize, "maximum dictionary size in bytes")
+	out := flag.String("o", "log.dict", "output file")
+	decode := flag.String("decode", "", "decode frames from stdin with this dictionary")
+	flag.Parse()
+	if *decode != "" {
+		dict, err := os.ReadFile(*decode)
+		if err == nil {
+			_, err = io.Copy(os.Stdout, logger.NewDeflateReader(os.Stdin, dict))
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: logdict [-size n] [-o file] log files...\n       logdict -decode dict < frames")
+		os.Exit(2)
+	}
+	dict, err := logger.TrainDictionaryFromFiles(flag.Args(), *size)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err = os.WriteFile(*out, dict, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("wrote %d bytes to %s\n", len(dict), *out)
+}
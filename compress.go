@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+const tmpSuffix = ".tmp"
+
+// Compression jobs queue without bound so rotation never waits on a slow
+// gzip; a single worker drains them in order.
+var (
+	compressMu      sync.Mutex
+	compressPending []compressJob
+	compressRunning bool
+	compressWG      sync.WaitGroup
+	compressing     sync.Map // path -> struct{}
+)
+
+type compressJob struct {
+	src  string
+	done func()
+}
+
+func enqueueCompress(src string, done func()) {
+	if _, loaded := compressing.LoadOrStore(src, struct{}{}); loaded {
+		return
+	}
+	compressWG.Add(1)
+	compressMu.Lock()
+	defer compressMu.Unlock()
+	compressPending = append(compressPending, compressJob{src, done})
+	if !compressRunning {
+		compressRunning = true
+		go compressLoop()
+	}
+}
+
+func compressLoop() {
+	for {
+		compressMu.Lock()
+		if len(compressPending) == 0 {
+			compressRunning = false
+			compressMu.Unlock()
+			return
+		}
+		job := compressPending[0]
+		compressPending = compressPending[1:]
+		compressMu.Unlock()
+		if err := compressFile(job.src); err != nil {
+			reportError("%s", err)
+		}
+		compressing.Delete(job.src)
+		if job.done != nil {
+			job.done()
+		}
+		compressWG.Done()
+	}
+}
+
+func waitCompress(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		compressWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func compressFile(src string) (err error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat log file: %v", err)
+	}
+	dst := src + compressSuffix
+	tmp := dst + tmpSuffix
+	gzf, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fi.Mode())
+	if err != nil {
+		return fmt.Errorf("failed to open compressed log file: %v", err)
+	}
+	defer gzf.Close()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+			err = fmt.Errorf("failed to compress log file %s: %v", src, err)
+		}
+	}()
+	gz := gzip.NewWriter(gzf)
+	if _, err = io.Copy(gz, f); err == nil {
+		if err = gz.Close(); err == nil {
+			if err = gzf.Sync(); err == nil {
+				if err = gzf.Close(); err == nil {
+					if err = os.Rename(tmp, dst); err == nil {
+						_ = f.Close()
+						err = os.Remove(src)
+					}
+				}
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const consoleFormat = "console"
+
+const (
+	colorReset = "\x1b[0m"
+	colorDim   = "\x1b[2m"
+	colorBold  = "\x1b[1m"
+)
+
+var (
+	processStart = time.Now()
+	levelColors  = map[Level]string{
+		TRACE:   "\x1b[90m",
+		INFO:    "\x1b[36m",
+		WARNING: "\x1b[33m",
+		ERROR:   "\x1b[31m",
+	}
+	// consoleKeys come first, in this order; other fields follow sorted by key.
+	consoleKeys = map[string]int{RequestIDField: 1, TenantField: 2, idField: 3}
+)
+
+type consoleEncoder struct {
+	units   Units
+	noColor bool
+	locale  locale
+}
+
+func newConsoleEncoder(units Units) consoleEncoder {
+	_, noColor := os.LookupEnv("NO_COLOR")
+	return consoleEncoder{units: units, noColor: noColor}
+}
+
+func (c consoleEncoder) withNames(map[string]string) encoder {
+	return c
+}
+
+func (c consoleEncoder) withUnits(units Units) encoder {
+	c.units = units
+	return c
+}
+
+func (consoleEncoder) needCaller(layout Layout) bool {
+	return layout.Caller != NoCaller
+}
+
+func (c consoleEncoder) color(buf []byte, color, s string) []byte {
+	if c.noColor || color == "" {
+		return append(buf, s...)
+	}
+	buf = append(buf, color...)
+	buf = append(buf, s...)
+	return append(buf, colorReset...)
+}
+
+func (consoleEncoder) sizeHint(e *Entry, _ string) int {
+	return estimateSize(e, "", 64, 24)
+}
+
+func (c consoleEncoder) encode(buf []byte, e *Entry, _ string, layout Layout) []byte {
+	ts := relativeTime(e.Time)
+	if c.locale.time != "" {
+		ts = e.Time.Local().Format(c.locale.time)
+	}
+	buf = c.color(buf, colorDim, ts)
+	buf = append(buf, ' ')
+	lvl := string(e.Level)
+	if len(lvl) > 4 {
+		lvl = lvl[:4]
+	}
+	buf = c.color(buf, levelColors[e.Level], lvl)
+	buf = append(buf, ' ')
+	if e.Logger != "" {
+		buf = c.color(buf, colorBold, e.Logger)
+		buf = append(buf, ' ')
+	}
+	if layout.Caller != NoCaller {
+		layout.Caller = ShortCaller
+		buf = c.color(buf, colorDim, callerFile(e.File, layout)+":"+string(itoa(nil, e.Line, -1)))
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, strings.TrimSuffix(e.Message, "\n")...)
+	var fields, blocks []Field
+	for _, field := range e.Fields {
+		fields = append(fields, expandField(field, c.units, UnitsHuman)...)
+	}
+	sortConsoleFields(fields)
+	for _, f := range fields {
+		if s, OK := f.Value.(string); OK && strings.Contains(s, "\n") {
+			blocks = append(blocks, f)
+			continue
+		}
+		buf = append(buf, ' ')
+		buf = c.color(buf, colorDim, f.Key+"=")
+		if n, OK := c.locale.number(f.Value); OK && c.locale.time != "" {
+			buf = append(buf, n...)
+		} else {
+			buf = appendTextValue(buf, f.Value)
+		}
+	}
+	buf = append(buf, '\n')
+	for _, f := range blocks {
+		buf = append(buf, "    "...)
+		buf = c.color(buf, colorDim, f.Key+":")
+		buf = append(buf, '\n')
+		for _, line := range strings.Split(strings.TrimRight(f.Value.(string), "\n"), "\n") {
+			buf = append(buf, "        "...)
+			line = strings.TrimSpace(line)
+			if i := strings.LastIndex(line, " +0x"); i > 0 {
+				line = line[:i]
+			}
+			buf = append(buf, line...)
+			buf = append(buf, '\n')
+		}
+	}
+	return buf
+}
+
+func sortConsoleFields(fields []Field) {
+	rank := func(key string) int {
+		if r, OK := consoleKeys[key]; OK {
+			return r
+		}
+		return len(consoleKeys) + 1
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		ri, rj := rank(fields[i].Key), rank(fields[j].Key)
+		if ri != rj {
+			return ri < rj
+		}
+		return ri > len(consoleKeys) && fields[i].Key < fields[j].Key
+	})
+}
+
+func relativeTime(t time.Time) string {
+	d := t.Sub(processStart)
+	if d < 0 {
+		d = 0
+	}
+	return "+" + d.Truncate(time.Millisecond).String()
+}
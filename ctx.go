@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const deadlineMargin = time.Millisecond
+
+func (l *Logger) PrintCtx(ctx context.Context, v ...interface{}) {
+	if l.enabledAt(ctx, 1, 0) {
+		_ = l.WithContext(ctx).output(2, fmt.Sprint(v...))
+	}
+}
+
+func (l *Logger) PrintfCtx(ctx context.Context, format string, v ...interface{}) {
+	if l.enabledAt(ctx, 1, 0) {
+		_ = l.WithContext(ctx).output(2, fmt.Sprintf(format, v...))
+	}
+}
+
+func (m *Loggers) TraceCtx(ctx context.Context, format string, v ...interface{}) {
+	m.Trace.printfCtx(ctx, format, v...)
+}
+
+func (m *Loggers) InfoCtx(ctx context.Context, format string, v ...interface{}) {
+	m.Info.printfCtx(ctx, format, v...)
+}
+
+func (m *Loggers) WarningCtx(ctx context.Context, format string, v ...interface{}) {
+	m.Warning.printfCtx(ctx, format, v...)
+}
+
+func (m *Loggers) ErrorCtx(ctx context.Context, format string, v ...interface{}) {
+	m.Error.printfCtx(ctx, format, v...)
+}
+
+func TraceCtx(ctx context.Context, format string, v ...interface{}) {
+	Trace.printfCtx(ctx, format, v...)
+}
+
+func InfoCtx(ctx context.Context, format string, v ...interface{}) {
+	Info.printfCtx(ctx, format, v...)
+}
+
+func WarningCtx(ctx context.Context, format string, v ...interface{}) {
+	Waring.printfCtx(ctx, format, v...)
+}
+
+func ErrorCtx(ctx context.Context, format string, v ...interface{}) {
+	Error.printfCtx(ctx, format, v...)
+}
+
+func (l *Logger) printfCtx(ctx context.Context, format string, v ...interface{}) {
+	if l.enabledAt(ctx, 2, 0) {
+		_ = l.WithContext(ctx).output(3, fmt.Sprintf(format, v...))
+	}
+}
+
+// writeBounded writes through the sinks, but never queues the caller past
+// the deadline of its context. Entries whose deadline is already (nearly)
+// spent, or that time out waiting for a busy sink, go to the fallback writer
+// instead of that sink. A write already in progress is not interrupted.
+func (l *Logger) writeBounded(e *Entry) (int, error) {
+	if l.ctx != nil {
+		if deadline, OK := l.ctx.Deadline(); OK && (time.Until(deadline) < deadlineMargin || l.ctx.Err() != nil) {
+			err := l.ctx.Err()
+			if err == nil {
+				err = context.DeadlineExceeded
+			}
+			fallback.write(e, err)
+			return 0, err
+		}
+	}
+	return l.writeSinks(e)
+}
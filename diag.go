@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	diagMinBackoff = time.Second
+	diagMaxBackoff = 5 * time.Minute
+)
+
+var diag = &diagnostics{out: os.Stdout, seen: map[string]*diagState{}}
+
+type diagnostics struct {
+	mu    sync.Mutex
+	out   io.Writer
+	seen  map[string]*diagState
+	sweep sync.Once
+}
+
+type diagState struct {
+	next       time.Time
+	backoff    time.Duration
+	suppressed int
+}
+
+func SetDiagnosticOutput(w io.Writer) {
+	diag.mu.Lock()
+	defer diag.mu.Unlock()
+	diag.out = w
+}
+
+// reportError prints internal errors, suppressing repeats of the same
+// message with exponential backoff and reporting how many were skipped.
+func reportError(format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	now := time.Now()
+	diag.sweep.Do(func() { go diag.sweepLoop() })
+	diag.mu.Lock()
+	defer diag.mu.Unlock()
+	s, OK := diag.seen[msg]
+	if !OK {
+		s = &diagState{}
+		diag.seen[msg] = s
+	}
+	if now.Before(s.next) {
+		s.suppressed++
+		return
+	}
+	if s.suppressed > 0 {
+		msg = fmt.Sprintf("%s (repeated %d times)", msg, s.suppressed+1)
+	}
+	if s.backoff = s.backoff * 2; s.backoff == 0 || now.Sub(s.next) > diagMaxBackoff {
+		s.backoff = diagMinBackoff
+	} else if s.backoff > diagMaxBackoff {
+		s.backoff = diagMaxBackoff
+	}
+	s.next, s.suppressed = now.Add(s.backoff), 0
+	_, _ = fmt.Fprintln(diag.out, msg)
+}
+
+// sweepLoop reports counts still pending once their backoff ends, so a storm
+// that stops is summarised too, and forgets messages quiet for a full
+// backoff period so the map does not grow with every distinct path or error.
+func (d *diagnostics) sweepLoop() {
+	ticker := time.NewTicker(diagMinBackoff)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		d.mu.Lock()
+		for msg, s := range d.seen {
+			switch {
+			case now.Before(s.next):
+			case s.suppressed > 0:
+				_, _ = fmt.Fprintf(d.out, "%s (repeated %d times)\n", msg, s.suppressed)
+				s.suppressed = 0
+			case now.After(s.next.Add(s.backoff)):
+				delete(d.seen, msg)
+			}
+		}
+		d.mu.Unlock()
+	}
+}
+
+// reportEvent writes a machine-readable record to the diagnostics output.
+// Events are never deduplicated.
+func reportEvent(kind string, v interface{}) {
+	b, err := json.Marshal(map[string]interface{}{"event": kind, "data": v})
+	if err != nil {
+		return
+	}
+	diag.mu.Lock()
+	defer diag.mu.Unlock()
+	_, _ = fmt.Fprintln(diag.out, string(b))
+}
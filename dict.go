@@ -0,0 +1,184 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultDictSize = 32 << 10
+	minDictToken    = 4
+	maxDictSamples  = 100000
+)
+
+// TrainDictionary builds a preset dictionary for deflate from sample entries.
+// Tokens that repeat across samples are scored by frequency times length and
+// the best ones are concatenated, most valuable last, since deflate reaches
+// the end of the dictionary with the shortest distances.
+func TrainDictionary(samples [][]byte, size int) []byte {
+	if size <= 0 || size > DefaultDictSize {
+		size = DefaultDictSize
+	}
+	counts := map[string]int{}
+	for _, s := range samples {
+		for _, token := range dictTokens(s) {
+			counts[token]++
+		}
+	}
+	type scored struct {
+		token string
+		score int
+	}
+	var tokens []scored
+	for token, n := range counts {
+		if n > 1 {
+			tokens = append(tokens, scored{token, n * len(token)})
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].score != tokens[j].score {
+			return tokens[i].score > tokens[j].score
+		}
+		return tokens[i].token < tokens[j].token
+	})
+	var picked []string
+	total := 0
+	for _, t := range tokens {
+		if total+len(t.token) > size {
+			continue
+		}
+		picked = append(picked, t.token)
+		total += len(t.token)
+	}
+	var dict bytes.Buffer
+	for i := len(picked) - 1; i >= 0; i-- {
+		dict.WriteString(picked[i])
+	}
+	return dict.Bytes()
+}
+
+func dictTokens(sample []byte) []string {
+	var tokens []string
+	start := 0
+	for i, c := range sample {
+		if c == ' ' || c == ',' || c == '\n' {
+			if i+1-start >= minDictToken {
+				tokens = append(tokens, string(sample[start:i+1]))
+			}
+			start = i + 1
+		}
+	}
+	if len(sample)-start >= minDictToken {
+		tokens = append(tokens, string(sample[start:]))
+	}
+	return tokens
+}
+
+// TrainDictionaryFromFiles samples entries, one per line, from existing log
+// files, gzip compressed ones included.
+func TrainDictionaryFromFiles(paths []string, size int) ([]byte, error) {
+	var samples [][]byte
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		var r io.Reader = f
+		if strings.HasSuffix(path, compressSuffix) {
+			if r, err = gzip.NewReader(f); err != nil {
+				f.Close()
+				return nil, err
+			}
+		}
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64<<10), 1<<20)
+		for scanner.Scan() && len(samples) < maxDictSamples {
+			samples = append(samples, append([]byte(nil), scanner.Bytes()...))
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return TrainDictionary(samples, size), nil
+}
+
+// deflateFramer compresses batches with a preset dictionary and frames each
+// with its big-endian uint32 length, so batches can be decoded independently.
+// One compressor is reused across batches.
+type deflateFramer struct {
+	dict []byte
+	buf  bytes.Buffer
+	w    *flate.Writer
+}
+
+func (d *deflateFramer) frame(dst, batch []byte) ([]byte, error) {
+	d.buf.Reset()
+	d.buf.Write([]byte{0, 0, 0, 0})
+	if d.w == nil {
+		w, err := flate.NewWriterDict(&d.buf, flate.BestSpeed, d.dict)
+		if err != nil {
+			return nil, err
+		}
+		d.w = w
+	} else {
+		d.w.Reset(&d.buf)
+	}
+	if _, err := d.w.Write(batch); err != nil {
+		return nil, err
+	}
+	if err := d.w.Close(); err != nil {
+		return nil, err
+	}
+	frame := d.buf.Bytes()
+	binary.BigEndian.PutUint32(frame, uint32(len(frame)-4))
+	return append(dst, frame...), nil
+}
+
+// NewDeflateReader decodes the frames sent by a ?compress=deflate out,
+// given the same dictionary, and returns the entries as one plain stream.
+func NewDeflateReader(r io.Reader, dict []byte) io.Reader {
+	return &deflateReader{r: r, dict: dict}
+}
+
+type deflateReader struct {
+	r     io.Reader
+	dict  []byte
+	frame *io.LimitedReader
+	fr    io.ReadCloser
+	hdr   [4]byte
+}
+
+func (d *deflateReader) Read(p []byte) (int, error) {
+	for {
+		if d.frame != nil {
+			n, err := d.fr.Read(p)
+			if err == io.EOF {
+				_, _ = io.Copy(io.Discard, d.frame)
+				d.frame = nil
+				err = nil
+			}
+			if n > 0 || err != nil {
+				return n, err
+			}
+			continue
+		}
+		if _, err := io.ReadFull(d.r, d.hdr[:]); err != nil {
+			return 0, err
+		}
+		d.frame = &io.LimitedReader{R: d.r, N: int64(binary.BigEndian.Uint32(d.hdr[:]))}
+		if d.fr == nil {
+			d.fr = flate.NewReaderDict(d.frame, d.dict)
+		} else if err := d.fr.(flate.Resetter).Reset(d.frame, d.dict); err != nil {
+			return 0, err
+		}
+	}
+}
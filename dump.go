@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	dumpPrefix     = "dump."
+	dumpTimeFormat = "20060102150405"
+	dumpKeep       = 5
+)
+
+var (
+	dumpMu       sync.Mutex
+	dumpProfiles = []string{"goroutine", "heap"}
+)
+
+// DumpProfiles writes goroutine and heap profiles next to the ERROR log
+// files, named dump.<time>.<profile>.pprof, keeping the last dumpKeep
+// dumps of each profile.
+func DumpProfiles() ([]string, error) {
+	dumpMu.Lock()
+	defer dumpMu.Unlock()
+	dir := dumpDir()
+	if err := os.MkdirAll(dir, os.ModeDir|0744); err != nil {
+		return nil, err
+	}
+	stamp := rotationNow().Format(dumpTimeFormat)
+	var paths []string
+	var errs []error
+	for _, name := range dumpProfiles {
+		path := filepath.Join(dir, fmt.Sprintf("%s%s.%s.pprof", dumpPrefix, stamp, name))
+		if err := writeProfile(name, path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		paths = append(paths, path)
+		pruneDumps(dir, name)
+	}
+	return paths, errors.Join(errs...)
+}
+
+func writeProfile(name, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = pprof.Lookup(name).WriteTo(f, 0)
+	if e := f.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+func dumpDir() string {
+	for _, l := range []*Logger{Error, Waring, Info, Trace} {
+		if l == nil {
+			continue
+		}
+		for _, rw := range l.rotatingWriters() {
+			return rw.dir
+		}
+	}
+	return "."
+}
+
+func pruneDumps(dir, name string) {
+	matches, _ := filepath.Glob(filepath.Join(dir, dumpPrefix+"*."+name+".pprof"))
+	if len(matches) <= dumpKeep {
+		return
+	}
+	sort.Strings(matches)
+	for _, path := range matches[:len(matches)-dumpKeep] {
+		if err := os.Remove(path); err != nil {
+			reportError("remove dump %s failed: %s", path, err)
+		}
+	}
+}
+
+// DumpHandler triggers DumpProfiles on POST and lists the written files.
+func DumpHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		paths, err := DumpProfiles()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = fmt.Fprintln(w, strings.Join(paths, "\n"))
+	})
+}
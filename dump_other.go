@@ -0,0 +1,6 @@
+//go:build !unix
+
+package logger
+
+// EnableDumpSignal is a no-op where SIGQUIT does not exist, use DumpHandler.
+func EnableDumpSignal() {}
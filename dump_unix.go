@@ -0,0 +1,25 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// EnableDumpSignal makes SIGQUIT write profiles with DumpProfiles instead
+// of the runtime's default of printing goroutines and exiting.
+func EnableDumpSignal() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGQUIT)
+	go func() {
+		for range ch {
+			if paths, err := DumpProfiles(); err != nil {
+				reportError("dump profiles failed: %s", err)
+			} else {
+				reportEvent("dump", paths)
+			}
+		}
+	}()
+}
@@ -0,0 +1,250 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	textFormat = "text"
+	jsonFormat = "json"
+)
+
+type encoder interface {
+	encode(buf []byte, e *Entry, prefix string, layout Layout) []byte
+	needCaller(layout Layout) bool
+	withUnits(units Units) encoder
+	sizeHint(e *Entry, prefix string) int
+	withNames(names map[string]string) encoder
+}
+
+func newEncoder(format string, units Units) (encoder, error) {
+	switch strings.ToLower(format) {
+	case textFormat, "":
+		return textEncoder{units: units}, nil
+	case jsonFormat:
+		return jsonEncoder{units: units}, nil
+	case consoleFormat:
+		return newConsoleEncoder(units), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+type textEncoder struct {
+	units Units
+}
+
+func (t textEncoder) withNames(map[string]string) encoder {
+	return t
+}
+
+func (t textEncoder) withUnits(units Units) encoder {
+	t.units = units
+	return t
+}
+
+func (textEncoder) needCaller(layout Layout) bool {
+	return layout.Caller != NoCaller
+}
+
+func (textEncoder) sizeHint(e *Entry, prefix string) int {
+	return estimateSize(e, prefix, 32, 16)
+}
+
+func (t textEncoder) encode(buf []byte, e *Entry, prefix string, layout Layout) []byte {
+	if !layout.MsgPrefix {
+		buf = appendPrefix(buf, prefix, e.Logger)
+	}
+	ts := e.Time
+	if layout.UTC {
+		ts = ts.UTC()
+	}
+	if layout.Date {
+		year, month, day := ts.Date()
+		buf = itoa(buf, year, 4)
+		buf = append(buf, '/')
+		buf = itoa(buf, int(month), 2)
+		buf = append(buf, '/')
+		buf = itoa(buf, day, 2)
+		buf = append(buf, ' ')
+	}
+	if layout.Time || layout.Micros {
+		hour, min, sec := ts.Clock()
+		buf = itoa(buf, hour, 2)
+		buf = append(buf, ':')
+		buf = itoa(buf, min, 2)
+		buf = append(buf, ':')
+		buf = itoa(buf, sec, 2)
+		if layout.Micros {
+			buf = append(buf, '.')
+			buf = itoa(buf, ts.Nanosecond()/1e3, 6)
+		}
+		buf = append(buf, ' ')
+	}
+	if layout.Caller != NoCaller {
+		buf = append(buf, callerFile(e.File, layout)...)
+		buf = append(buf, ':')
+		buf = itoa(buf, e.Line, -1)
+		buf = append(buf, ": "...)
+	}
+	if layout.MsgPrefix {
+		buf = appendPrefix(buf, prefix, e.Logger)
+	}
+	buf = append(buf, strings.TrimSuffix(e.Message, "\n")...)
+	for _, field := range e.Fields {
+		for _, f := range expandField(field, t.units, UnitsHuman) {
+			buf = append(buf, ' ')
+			buf = append(buf, f.Key...)
+			buf = append(buf, '=')
+			buf = appendTextValue(buf, f.Value)
+		}
+	}
+	return append(buf, '\n')
+}
+
+// appendPrefix renders the label and logger name as "[label] [name] ".
+// Both are kept raw on the logger, so reconfiguring never wraps them twice.
+func appendPrefix(buf []byte, prefix, name string) []byte {
+	for _, s := range [2]string{prefix, name} {
+		if s != "" {
+			buf = append(buf, '[')
+			buf = append(buf, s...)
+			buf = append(buf, "] "...)
+		}
+	}
+	return buf
+}
+
+func appendTextValue(buf []byte, v interface{}) []byte {
+	var s string
+	switch val := v.(type) {
+	case []string:
+		buf = append(buf, '[')
+		for i, item := range val {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = strconv.AppendQuote(buf, item)
+		}
+		return append(buf, ']')
+	case string:
+		s = val
+	case error:
+		s = val.Error()
+	case fmt.Stringer:
+		s = val.String()
+	default:
+		s = fmt.Sprint(val)
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+type jsonEncoder struct {
+	units Units
+	names map[string]string
+}
+
+func (j jsonEncoder) withNames(names map[string]string) encoder {
+	j.names = names
+	return j
+}
+
+func (j jsonEncoder) key(buf []byte, key string, first bool) []byte {
+	if !first {
+		buf = append(buf, ',')
+	}
+	if name, OK := j.names[key]; OK {
+		key = name
+	}
+	buf = appendJSONString(buf, key)
+	return append(buf, ':')
+}
+
+func (j jsonEncoder) withUnits(units Units) encoder {
+	j.units = units
+	return j
+}
+
+func (jsonEncoder) needCaller(Layout) bool {
+	return true
+}
+
+func (jsonEncoder) sizeHint(e *Entry, _ string) int {
+	return estimateSize(e, "", 96, 24)
+}
+
+func (j jsonEncoder) encode(buf []byte, e *Entry, _ string, layout Layout) []byte {
+	t := e.Time
+	if layout.UTC {
+		t = t.UTC()
+	}
+	buf = append(buf, '{')
+	buf = j.key(buf, "timestamp", true)
+	buf = strconv.AppendQuote(buf, t.Format(time.RFC3339Nano))
+	buf = j.key(buf, "level", false)
+	buf = strconv.AppendQuote(buf, string(e.Level))
+	if e.Logger != "" {
+		buf = j.key(buf, "logger", false)
+		buf = appendJSONString(buf, e.Logger)
+	}
+	buf = j.key(buf, "caller", false)
+	buf = strconv.AppendQuote(buf, callerFile(e.File, layout)+":"+strconv.Itoa(e.Line))
+	buf = j.key(buf, "message", false)
+	buf = appendJSONString(buf, strings.TrimSuffix(e.Message, "\n"))
+	for _, field := range e.Fields {
+		for _, f := range expandField(field, j.units, UnitsRaw) {
+			buf = append(buf, ',')
+			buf = appendJSONString(buf, f.Key)
+			buf = append(buf, ':')
+			buf = appendJSONValue(buf, f.Value)
+		}
+	}
+	return append(buf, "}\n"...)
+}
+
+func appendJSONString(buf []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(buf, b...)
+}
+
+func appendJSONValue(buf []byte, v interface{}) []byte {
+	if err, OK := v.(error); OK {
+		return appendJSONString(buf, err.Error())
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return appendJSONString(buf, fmt.Sprint(v))
+	}
+	return append(buf, b...)
+}
+
+func callerFile(file string, layout Layout) string {
+	if layout.Caller == LongCaller {
+		return file
+	}
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		return file[i+1:]
+	}
+	return file
+}
+
+func itoa(buf []byte, i int, wid int) []byte {
+	var b [20]byte
+	bp := len(b) - 1
+	for i >= 10 || wid > 1 {
+		wid--
+		q := i / 10
+		b[bp] = byte('0' + i - q*10)
+		bp--
+		i = q
+	}
+	b[bp] = byte('0' + i)
+	return append(buf, b[bp:]...)
+}
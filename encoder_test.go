@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var prefixEntry = Entry{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Level: INFO, Logger: "db", Message: "hello"}
+
+func TestPrefixRendering(t *testing.T) {
+	cases := []struct {
+		name   string
+		enc    encoder
+		layout Layout
+		want   string
+	}{
+		{"text", textEncoder{}, Layout{}, "[INFO] [db] hello\n"},
+		{"text msgprefix", textEncoder{}, Layout{Time: true, MsgPrefix: true}, "03:04:05 [INFO] [db] hello\n"},
+		{"json", jsonEncoder{}, Layout{}, `{"timestamp":"2024-01-02T03:04:05Z","level":"INFO","logger":"db","caller":":0","message":"hello"}` + "\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := prefixEntry
+			if got := string(c.enc.encode(nil, &e, "INFO", c.layout)); got != c.want {
+				t.Fatalf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFallbackPrefix(t *testing.T) {
+	var out bytes.Buffer
+	f := &fallbackWriter{out: &out, limit: fallbackLimit}
+	e := prefixEntry
+	e.File, e.Line = "/src/db.go", 7
+	f.write(&e, errors.New("disk full"))
+	if got := out.String(); !strings.HasPrefix(got, "2024/01/02 03:04:05 db.go:7: [FALLBACK] [db] hello") || strings.Contains(got, "[INFO]") {
+		t.Fatalf("fallback rendered %q", got)
+	}
+}
+
+// keepGlobals restores the configs and loggers that presets rewrite.
+func keepGlobals(t *testing.T) {
+	saved := map[Level]*loggerConfig{}
+	for lvl, c := range configs {
+		saved[lvl] = c.clone()
+	}
+	all, level := allConfig.clone(), GetLevel()
+	trace, info, waring, errLogger := Trace, Info, Waring, Error
+	overrides := map[string][]override{}
+	for name, o := range moduleOverrides {
+		overrides[name] = append([]override(nil), o...)
+	}
+	modulesMu.Lock()
+	mods := modules
+	modulesMu.Unlock()
+	t.Cleanup(func() {
+		modulesMu.Lock()
+		defer modulesMu.Unlock()
+		for lvl, c := range saved {
+			*configs[lvl] = *c
+		}
+		*allConfig = *all
+		Trace, Info, Waring, Error = trace, info, waring, errLogger
+		moduleOverrides, modules = overrides, mods
+		SetLevel(level)
+	})
+}
+
+func TestPrefixIdempotent(t *testing.T) {
+	keepGlobals(t)
+	config := defaultConfig(INFO)
+	config.name = "db"
+	first := config.Create()
+	second := config.Create()
+	if first.Prefix() != "INFO" || second.Prefix() != "INFO" || config.prefix != "INFO" {
+		t.Fatalf("prefixes %q %q, config %q", first.Prefix(), second.Prefix(), config.prefix)
+	}
+	render := func(l *Logger) string {
+		e := prefixEntry
+		return string(l.enc.encode(nil, &e, l.Prefix(), Layout{}))
+	}
+	before := render(first)
+	for i := 0; i < 3; i++ {
+		first.SetPrefix(first.Prefix())
+	}
+	if after := render(first); after != before {
+		t.Fatalf("SetPrefix(Prefix()) changed output from %q to %q", before, after)
+	}
+	for i := 0; i < 2; i++ {
+		Dev()
+		Serverless()
+	}
+	for _, l := range []*Logger{Trace, Info, Waring, Error} {
+		if l.Prefix() != string(l.level) {
+			t.Fatalf("%s prefix after presets = %q", l.level, l.Prefix())
+		}
+	}
+}
+
+func BenchmarkPrefix(b *testing.B) {
+	enc := textEncoder{}
+	e := prefixEntry
+	buf := make([]byte, 0, 256)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = enc.encode(buf[:0], &e, "INFO", Layout{})
+	}
+}
+
+func TestConsoleFieldOrder(t *testing.T) {
+	e := prefixEntry
+	e.Fields = []Field{{Key: "zone", Value: "b"}, {Key: TenantField, Value: "acme"}, {Key: "attempt", Value: 2}, {Key: RequestIDField, Value: "r1"}, {Key: "attempt", Value: 3}}
+	got := string(consoleEncoder{noColor: true}.encode(nil, &e, "INFO", Layout{Caller: NoCaller}))
+	if want := " hello request_id=r1 tenant=acme attempt=2 attempt=3 zone=b\n"; !strings.HasSuffix(got, want) {
+		t.Fatalf("got %q, want suffix %q", got, want)
+	}
+}
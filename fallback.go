@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const fallbackLimit = 100
+
+var fallbackLayout = Layout{Date: true, Time: true, Caller: ShortCaller, MsgPrefix: true}
+
+var fallback = &fallbackWriter{out: os.Stderr, limit: fallbackLimit}
+
+type fallbackWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	buf     []byte
+	limit   int
+	window  time.Time
+	count   int
+	dropped int
+}
+
+func (f *fallbackWriter) write(e *Entry, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if now.Sub(f.window) >= time.Second {
+		if f.dropped > 0 {
+			_, _ = fmt.Fprintf(f.out, "[FALLBACK] %d entries dropped by rate limit\n", f.dropped)
+		}
+		f.window, f.count, f.dropped = now, 0, 0
+	}
+	if f.count >= f.limit {
+		f.dropped++
+		return
+	}
+	f.count++
+	f.buf = textEncoder{}.encode(f.buf[:0], e, "FALLBACK", fallbackLayout)
+	f.buf = append(f.buf[:len(f.buf)-1], fmt.Sprintf(" level=%s sink_error=%q\n", e.Level, err)...)
+	_, _ = f.out.Write(f.buf)
+}
@@ -0,0 +1,60 @@
+package logger
+
+import "sync"
+
+const badKey = "!BADKEY"
+
+// fieldList is the immutable chain of fields built by nested With calls.
+// Children share their parent's node; the merged view is computed once per
+// node. A child's key replaces the parent's value in the parent's position,
+// new keys follow in the order they were added.
+type fieldList struct {
+	parent *fieldList
+	own    []Field
+	once   sync.Once
+	flat   []Field
+}
+
+func (f *fieldList) with(own []Field) *fieldList {
+	if len(own) == 0 {
+		return f
+	}
+	return &fieldList{parent: f, own: own}
+}
+
+func (f *fieldList) fields() []Field {
+	if f == nil {
+		return nil
+	}
+	f.once.Do(func() {
+		f.flat = mergeFields(f.parent.fields(), f.own)
+	})
+	return f.flat[:len(f.flat):len(f.flat)]
+}
+
+func mergeFields(base, own []Field) []Field {
+	merged := make([]Field, len(base), len(base)+len(own))
+	copy(merged, base)
+	var index map[string]int
+	for _, field := range own {
+		if field.Key == badKey {
+			merged = append(merged, field)
+			continue
+		}
+		if index == nil {
+			index = make(map[string]int, len(merged)+len(own))
+			for i, m := range merged {
+				if m.Key != badKey {
+					index[m.Key] = i
+				}
+			}
+		}
+		if i, OK := index[field.Key]; OK {
+			merged[i].Value = field.Value
+			continue
+		}
+		index[field.Key] = len(merged)
+		merged = append(merged, field)
+	}
+	return merged
+}
@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type LogFile struct {
+	Path       string
+	Period     time.Time
+	Index      int
+	Size       int64
+	Compressed bool
+	Current    bool
+}
+
+func (f LogFile) Checksum() (string, error) {
+	r, err := os.Open(f.Path)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (f LogFile) Compress() error {
+	if f.Current {
+		return fmt.Errorf("%s is being written", f.Path)
+	}
+	if f.Compressed {
+		return nil
+	}
+	if _, busy := compressing.LoadOrStore(f.Path, struct{}{}); busy {
+		return fmt.Errorf("%s is being compressed", f.Path)
+	}
+	defer compressing.Delete(f.Path)
+	return compressFile(f.Path)
+}
+
+func (f LogFile) Delete() error {
+	if f.Current {
+		return fmt.Errorf("%s is being written", f.Path)
+	}
+	return os.Remove(f.Path)
+}
+
+func (l *RotatingWriter) Files() ([]LogFile, error) {
+	l.mu.Lock()
+	current := ""
+	if l.file != nil {
+		current = l.file.Name()
+	}
+	l.mu.Unlock()
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []LogFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		t, index, e := l.parseName(entry.Name())
+		if e != nil {
+			continue
+		}
+		info, e := entry.Info()
+		if e != nil {
+			continue
+		}
+		path := filepath.Join(l.dir, entry.Name())
+		files = append(files, LogFile{
+			Path:       path,
+			Period:     t,
+			Index:      index,
+			Size:       info.Size(),
+			Compressed: strings.HasSuffix(path, compressSuffix),
+			Current:    path == current,
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].Period.Equal(files[j].Period) {
+			return files[i].Period.Before(files[j].Period)
+		}
+		return files[i].Index < files[j].Index
+	})
+	return files, nil
+}
+
+// Reopen closes the current file, the next write opens it again. Useful
+// after the file was moved or removed by an external tool.
+func (l *RotatingWriter) Reopen() error {
+	return l.Close()
+}
+
+func rotatingWriter(w io.Writer) *RotatingWriter {
+	switch rw := w.(type) {
+	case *RotatingWriter:
+		return rw
+	case *bufferedWriter:
+		return rotatingWriter(rw.out)
+	case *journalWriter:
+		return rw.out
+	}
+	return nil
+}
+
+func (l *Logger) rotatingWriters() []*RotatingWriter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var writers []*RotatingWriter
+	for _, s := range l.sinks {
+		if rw := rotatingWriter(s.w); rw != nil {
+			writers = append(writers, rw)
+		}
+	}
+	return writers
+}
+
+func (l *Logger) Files() ([]LogFile, error) {
+	var files []LogFile
+	var errs []error
+	for _, rw := range l.rotatingWriters() {
+		f, err := rw.Files()
+		files = append(files, f...)
+		errs = append(errs, err)
+	}
+	return files, errors.Join(errs...)
+}
+
+func (l *Logger) Reopen() error {
+	var errs []error
+	for _, rw := range l.rotatingWriters() {
+		errs = append(errs, rw.Reopen())
+	}
+	return errors.Join(errs...)
+}
+
+func Files(level Level) ([]LogFile, error) {
+	l := globalLogger(level)
+	if l == nil {
+		return nil, fmt.Errorf("unknown level %q", level)
+	}
+	return l.Files()
+}
+
+func globalLogger(level Level) *Logger {
+	switch level {
+	case TRACE:
+		return Trace
+	case INFO:
+		return Info
+	case WARNING:
+		return Waring
+	case ERROR:
+		return Error
+	}
+	return nil
+}
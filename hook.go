@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+const hookQueueSize = 1024
+
+type HookOption func(*hook)
+
+func HookAsync() HookOption {
+	return func(h *hook) {
+		h.async = true
+	}
+}
+
+func HookBeforeWrite() HookOption {
+	return func(h *hook) {
+		h.before = true
+	}
+}
+
+type hook struct {
+	levels map[Level]bool
+	fn     func(Entry)
+	async  bool
+	before bool
+	queue  chan Entry
+}
+
+var (
+	hooksMu     sync.Mutex
+	hooks       atomic.Value // []*hook
+	hookDropped int64
+)
+
+func AddHook(levels []Level, fn func(Entry), opts ...HookOption) {
+	h := &hook{levels: make(map[Level]bool, len(levels)), fn: fn}
+	for _, lvl := range levels {
+		h.levels[lvl] = true
+	}
+	for _, o := range opts {
+		o(h)
+	}
+	if h.async {
+		h.queue = make(chan Entry, hookQueueSize)
+		go func() {
+			for e := range h.queue {
+				h.call(e)
+			}
+		}()
+	}
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	old, _ := hooks.Load().([]*hook)
+	hooks.Store(append(append([]*hook(nil), old...), h))
+}
+
+func runHooks(e *Entry, before bool) {
+	hs, _ := hooks.Load().([]*hook)
+	for _, h := range hs {
+		if h.before != before || len(h.levels) > 0 && !h.levels[e.Level] {
+			continue
+		}
+		if !h.async {
+			h.call(*e)
+			continue
+		}
+		c := *e
+		c.Fields = append([]Field(nil), e.Fields...)
+		select {
+		case h.queue <- c:
+		default:
+			atomic.AddInt64(&hookDropped, 1)
+			reportError("hook queue is full, drop entry")
+		}
+	}
+}
+
+func (h *hook) call(e Entry) {
+	defer func() {
+		if r := recover(); r != nil {
+			reportError("hook panic: %v", r)
+		}
+	}()
+	h.fn(e)
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DebugHeader = "X-Debug-Log"
+
+func SignDebugHeader(secret []byte, l Level, ttl time.Duration) string {
+	payload := fmt.Sprintf("%s:%d", strings.ToLower(string(l)), time.Now().Add(ttl).Unix())
+	return payload + ":" + debugSignature(secret, payload)
+}
+
+func DebugMiddleware(secret []byte, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if value := r.Header.Get(DebugHeader); value != "" && len(secret) > 0 {
+			if l, err := verifyDebugHeader(secret, value); err == nil {
+				r = r.WithContext(ContextWithLevel(r.Context(), l))
+			} else {
+				Waring.Printf("ignore %s header from %s: %s", DebugHeader, r.RemoteAddr, err)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func verifyDebugHeader(secret []byte, value string) (Level, error) {
+	i := strings.LastIndexByte(value, ':')
+	if i < 0 {
+		return "", fmt.Errorf("malformed value")
+	}
+	payload, sig := value[:i], value[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(debugSignature(secret, payload))) {
+		return "", fmt.Errorf("invalid signature")
+	}
+	parts := strings.SplitN(payload, ":", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("malformed value")
+	}
+	l := Level(strings.ToUpper(parts[0]))
+	if l.severity() < 0 {
+		return "", fmt.Errorf("unknown level %q", parts[0])
+	}
+	expire, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("malformed expiry %q", parts[1])
+	}
+	if time.Now().Unix() > expire {
+		return "", fmt.Errorf("expired")
+	}
+	return l, nil
+}
+
+func debugSignature(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const idField = "id"
+
+type IDGenerator interface {
+	NewID() string
+}
+
+type IDFunc func() string
+
+func (f IDFunc) NewID() string {
+	return f()
+}
+
+func newIDGenerator(name string) (IDGenerator, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "none":
+		return nil, nil
+	case "ulid":
+		return ULID(), nil
+	case "uuid", "uuidv7":
+		return UUIDv7(), nil
+	case "snowflake":
+		return Snowflake(0), nil
+	}
+	if strings.HasPrefix(name, "snowflake:") {
+		if node, err := strconv.ParseInt(strings.TrimPrefix(name, "snowflake:"), 10, 64); err == nil {
+			return Snowflake(node), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown id generator %q", name)
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func ULID() IDGenerator {
+	return IDFunc(func() string {
+		var b [16]byte
+		binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+		_, _ = rand.Read(b[6:])
+		var out [26]byte
+		hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+		for i := 25; i >= 0; i-- {
+			out[i] = crockford[lo&31]
+			lo = lo>>5 | hi<<59
+			hi >>= 5
+		}
+		return string(out[:])
+	})
+}
+
+func UUIDv7() IDGenerator {
+	return IDFunc(func() string {
+		var b [16]byte
+		binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+		_, _ = rand.Read(b[6:])
+		b[6] = b[6]&0x0f | 0x70
+		b[8] = b[8]&0x3f | 0x80
+		var out [36]byte
+		hex.Encode(out[0:8], b[0:4])
+		out[8] = '-'
+		hex.Encode(out[9:13], b[4:6])
+		out[13] = '-'
+		hex.Encode(out[14:18], b[6:8])
+		out[18] = '-'
+		hex.Encode(out[19:23], b[8:10])
+		out[23] = '-'
+		hex.Encode(out[24:], b[10:])
+		return string(out[:])
+	})
+}
+
+var snowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+type snowflake struct {
+	mu   sync.Mutex
+	node int64
+	last int64
+	seq  int64
+}
+
+func Snowflake(node int64) IDGenerator {
+	return &snowflake{node: node & 0x3ff}
+}
+
+func (s *snowflake) NewID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UnixMilli() - snowflakeEpoch
+	if now < s.last {
+		now = s.last
+	}
+	if now == s.last {
+		if s.seq = (s.seq + 1) & 0xfff; s.seq == 0 {
+			for now <= s.last {
+				now = time.Now().UnixMilli() - snowflakeEpoch
+			}
+		}
+	} else {
+		s.seq = 0
+	}
+	s.last = now
+	return strconv.FormatInt(now<<22|s.node<<12|s.seq, 10)
+}
+
+func (l *Logger) SetIDGenerator(g IDGenerator) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.idGen = g
+}
+
+func (l *Logger) stampID(e *Entry) {
+	l.mu.Lock()
+	g := l.idGen
+	l.mu.Unlock()
+	if g != nil {
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], Field{Key: idField, Value: g.NewID()})
+	}
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Identity map[string]string
+
+type identitySnapshot struct {
+	values Identity
+	fields []Field
+}
+
+var (
+	identity     atomic.Value // identitySnapshot
+	identityMu   sync.Mutex
+	identityStop chan struct{}
+)
+
+// SetIdentityProvider attaches the values returned by fn as fields to every
+// entry and refreshes them every refresh interval. The values can also be
+// used as sharedtag names for files opened after the provider was set.
+func SetIdentityProvider(fn func() Identity, refresh time.Duration) {
+	identityMu.Lock()
+	defer identityMu.Unlock()
+	if identityStop != nil {
+		close(identityStop)
+		identityStop = nil
+	}
+	if fn == nil {
+		identity.Store(identitySnapshot{})
+		return
+	}
+	storeIdentity(fn())
+	if refresh <= 0 {
+		return
+	}
+	stop := make(chan struct{})
+	identityStop = stop
+	go func() {
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				storeIdentity(fn())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+func storeIdentity(values Identity) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]Field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, Field{Key: k, Value: values[k]})
+	}
+	identity.Store(identitySnapshot{values: values, fields: fields})
+}
+
+func currentIdentity() identitySnapshot {
+	s, _ := identity.Load().(identitySnapshot)
+	return s
+}
+
+func stampIdentity(e *Entry) {
+	if fields := currentIdentity().fields; len(fields) > 0 {
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], fields...)
+	}
+}
+
+func identityTag(tags []string) string {
+	values := currentIdentity().values
+	var parts []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if v, OK := values[tag]; OK && v != "" {
+			parts = append(parts, strings.ReplaceAll(v, ".", "-"))
+			continue
+		}
+		switch tag {
+		case "hostname":
+			if host, err := os.Hostname(); err == nil && host != "" {
+				parts = append(parts, strings.ReplaceAll(host, ".", "-"))
+			}
+		case "pid":
+			parts = append(parts, strconv.Itoa(os.Getpid()))
+		}
+	}
+	return strings.Join(parts, ".")
+}
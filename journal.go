@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	journalSuffix   = ".journal"
+	journalBatch    = 64 << 10
+	journalInterval = time.Second
+)
+
+// journalWriter makes every entry durable in a small fsync'd journal and
+// moves entries to the main file in batches, fsyncing it once per batch.
+// A journal left behind by a crash is replayed into the main file when the
+// writer is created, so entries are delivered at least once.
+type journalWriter struct {
+	mu      sync.Mutex
+	out     *RotatingWriter
+	journal *os.File
+	pending []byte
+	stop    chan struct{}
+	once    sync.Once
+}
+
+func newJournalWriter(out *RotatingWriter, path string) (*journalWriter, error) {
+	if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+		if _, err = out.Write(data); err != nil {
+			return nil, err
+		}
+		if err = out.Flush(); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModeDir|0744); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	j := &journalWriter{out: out, journal: f, stop: make(chan struct{})}
+	go j.loop()
+	return j, nil
+}
+
+func (j *journalWriter) loop() {
+	ticker := time.NewTicker(journalInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := j.Flush(); err != nil {
+				reportError("journal flush failed: %s", err)
+			}
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+func (j *journalWriter) Write(p []byte) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.journal.Write(p); err != nil {
+		return 0, err
+	}
+	if err := j.journal.Sync(); err != nil {
+		return 0, err
+	}
+	j.pending = append(j.pending, p...)
+	if len(j.pending) >= journalBatch {
+		if err := j.flush(); err != nil {
+			reportError("journal flush failed: %s", err)
+		}
+	}
+	return len(p), nil
+}
+
+func (j *journalWriter) flush() error {
+	if len(j.pending) == 0 {
+		return nil
+	}
+	if _, err := j.out.Write(j.pending); err != nil {
+		return err
+	}
+	if err := j.out.Flush(); err != nil {
+		return err
+	}
+	j.pending = j.pending[:0]
+	return j.journal.Truncate(0)
+}
+
+func (j *journalWriter) Flush() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.flush()
+}
+
+func (j *journalWriter) Close() error {
+	j.once.Do(func() { close(j.stop) })
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	err := j.flush()
+	if e := j.journal.Close(); e != nil && err == nil {
+		err = e
+	}
+	if e := j.out.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
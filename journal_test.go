@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournalReplay(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	journal := path + journalSuffix
+	if err := os.WriteFile(journal, []byte("a\nb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	j, err := newJournalWriter(newRotatingWriter(path), journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(journal); len(data) != 0 {
+		t.Fatalf("journal after replay = %q, want it truncated", data)
+	}
+	if _, err = j.Write([]byte("c\n")); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a crash: stop without moving pending entries to the main file.
+	j.once.Do(func() { close(j.stop) })
+	_ = j.journal.Close()
+	_ = j.out.Close()
+
+	j, err = newJournalWriter(newRotatingWriter(path), journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files, err := j.out.Files()
+	if err != nil || len(files) != 1 {
+		t.Fatalf("files = %+v, %v; want one log file", files, err)
+	}
+	if data, _ := os.ReadFile(files[0].Path); string(data) != "a\nb\nc\n" {
+		t.Fatalf("main file = %q, want every journaled entry once", data)
+	}
+	if data, _ := os.ReadFile(journal); len(data) != 0 {
+		t.Fatalf("journal after close = %q, want it empty", data)
+	}
+}
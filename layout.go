@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+type caller int
+
+const (
+	NoCaller caller = iota
+	ShortCaller
+	LongCaller
+)
+
+type Layout struct {
+	Date, Time, Micros bool
+	Caller             caller
+	MsgPrefix          bool
+	UTC                bool
+}
+
+func layoutFromFlags(flag int) Layout {
+	layout := Layout{
+		Date:      flag&log.Ldate != 0,
+		Time:      flag&(log.Ltime|log.Lmicroseconds) != 0,
+		Micros:    flag&log.Lmicroseconds != 0,
+		MsgPrefix: flag&log.Lmsgprefix != 0,
+		UTC:       flag&log.LUTC != 0,
+	}
+	if flag&log.Lshortfile != 0 {
+		layout.Caller = ShortCaller
+	} else if flag&log.Llongfile != 0 {
+		layout.Caller = LongCaller
+	}
+	return layout
+}
+
+func (l Layout) Flags() int {
+	var flag int
+	if l.Date {
+		flag |= log.Ldate
+	}
+	if l.Time {
+		flag |= log.Ltime
+	}
+	if l.Micros {
+		flag |= log.Lmicroseconds
+	}
+	switch l.Caller {
+	case ShortCaller:
+		flag |= log.Lshortfile
+	case LongCaller:
+		flag |= log.Llongfile
+	}
+	if l.MsgPrefix {
+		flag |= log.Lmsgprefix
+	}
+	if l.UTC {
+		flag |= log.LUTC
+	}
+	return flag
+}
+
+func parseLayout(s string) (Layout, error) {
+	var layout Layout
+	for _, item := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(item)) {
+		case "date":
+			layout.Date = true
+		case "time":
+			layout.Time = true
+		case "micros":
+			layout.Time, layout.Micros = true, true
+		case "shortfile", "caller":
+			layout.Caller = ShortCaller
+		case "longfile":
+			layout.Caller = LongCaller
+		case "msgprefix":
+			layout.MsgPrefix = true
+		case "utc":
+			layout.UTC = true
+		case "", "none":
+		default:
+			return Layout{}, fmt.Errorf("unknown layout item %q", item)
+		}
+	}
+	return layout, nil
+}
+
+func (l Layout) String() string {
+	var items []string
+	for _, item := range []struct {
+		on   bool
+		name string
+	}{
+		{l.Date, "date"}, {l.Time && !l.Micros, "time"}, {l.Micros, "micros"},
+		{l.Caller == ShortCaller, "shortfile"}, {l.Caller == LongCaller, "longfile"},
+		{l.MsgPrefix, "msgprefix"}, {l.UTC, "utc"},
+	} {
+		if item.on {
+			items = append(items, item.name)
+		}
+	}
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ",")
+}
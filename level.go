@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type Level string
+
+const (
+	TRACE   Level = "TRACE"
+	INFO    Level = "INFO"
+	WARNING Level = "WARNING"
+	ERROR   Level = "ERROR"
+)
+
+const defaultLevel = TRACE
+
+func (l Level) severity() int32 {
+	switch l {
+	case TRACE:
+		return 0
+	case INFO:
+		return 1
+	case WARNING:
+		return 2
+	case ERROR:
+		return 3
+	}
+	return -1
+}
+
+var (
+	minSeverity   int32
+	pkgLevels     atomic.Value // map[string]int32
+	pkgLevelMu    sync.Mutex
+	callerLevels  atomic.Value // *sync.Map, pc -> int32
+	levelSequence = []Level{TRACE, INFO, WARNING, ERROR}
+)
+
+func SetLevel(l Level) {
+	if s := l.severity(); s >= 0 {
+		atomic.StoreInt32(&minSeverity, s)
+	}
+}
+
+func GetLevel() Level {
+	return levelSequence[atomic.LoadInt32(&minSeverity)]
+}
+
+func SetPackageLevel(pkg string, l Level) {
+	s := l.severity()
+	if s < 0 || pkg == "" {
+		return
+	}
+	pkgLevelMu.Lock()
+	defer pkgLevelMu.Unlock()
+	old, _ := pkgLevels.Load().(map[string]int32)
+	levels := make(map[string]int32, len(old)+1)
+	for k, v := range old {
+		levels[k] = v
+	}
+	levels[pkg] = s
+	pkgLevels.Store(levels)
+	callerLevels.Store(&sync.Map{})
+}
+
+type levelKey struct{}
+
+func ContextWithLevel(ctx context.Context, l Level) context.Context {
+	if l.severity() < 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, levelKey{}, l)
+}
+
+func LevelFromContext(ctx context.Context) (Level, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	l, OK := ctx.Value(levelKey{}).(Level)
+	return l, OK
+}
+
+func (l *Logger) SetLevel(lvl Level) {
+	if s := lvl.severity(); s >= 0 {
+		atomic.StoreInt32(&l.minSeverity, s)
+	}
+}
+
+func (l *Logger) ResetLevel() {
+	atomic.StoreInt32(&l.minSeverity, -1)
+}
+
+func (l *Logger) Mute() {
+	atomic.StoreInt32(&l.muted, 1)
+}
+
+func (l *Logger) Unmute() {
+	atomic.StoreInt32(&l.muted, 0)
+}
+
+func (l *Logger) enabled(skip int) bool {
+	return l.enabledAt(l.ctx, skip+1, 0)
+}
+
+func (l *Logger) enabledAt(ctx context.Context, skip int, pc uintptr) bool {
+	if atomic.LoadInt32(&l.muted) != 0 {
+		return false
+	}
+	s := l.level.severity()
+	if ctxLevel, OK := LevelFromContext(ctx); OK && s >= ctxLevel.severity() {
+		return true
+	}
+	if cache, _ := callerLevels.Load().(*sync.Map); cache != nil {
+		if pc == 0 {
+			var pcs [1]uintptr
+			if runtime.Callers(skip+2, pcs[:]) > 0 {
+				pc = pcs[0]
+			}
+		}
+		if override := callerSeverity(cache, pc); override >= 0 {
+			return s >= override
+		}
+	}
+	if min := atomic.LoadInt32(&l.minSeverity); min >= 0 {
+		return s >= min
+	}
+	return s >= atomic.LoadInt32(&minSeverity)
+}
+
+func (l *Logger) mayEnable(ctx context.Context) bool {
+	if atomic.LoadInt32(&l.muted) != 0 {
+		return false
+	}
+	if callerLevels.Load() != nil {
+		return true
+	}
+	return l.enabledAt(ctx, 0, 0)
+}
+
+func callerSeverity(cache *sync.Map, pc uintptr) int32 {
+	if pc == 0 {
+		return -1
+	}
+	if v, OK := cache.Load(pc); OK {
+		return v.(int32)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkg := funcPackage(frame.Function)
+	levels, _ := pkgLevels.Load().(map[string]int32)
+	s, match := int32(-1), ""
+	for name, v := range levels {
+		if matchPackage(pkg, name) && len(name) > len(match) {
+			match, s = name, v
+		}
+	}
+	cache.Store(pc, s)
+	return s
+}
+
+func funcPackage(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	if dot := strings.IndexByte(function[slash+1:], '.'); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+func matchPackage(pkg, name string) bool {
+	return pkg == name || strings.HasSuffix(pkg, "/"+name) ||
+		strings.HasPrefix(pkg, name+"/") || strings.Contains(pkg, "/"+name+"/")
+}
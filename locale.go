@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type locale struct {
+	thousands, decimal string
+	time               string
+}
+
+var locales = map[string]locale{
+	"en":    {",", ".", "01/02 15:04:05"},
+	"en-gb": {",", ".", "02/01 15:04:05"},
+	"de":    {".", ",", "02.01. 15:04:05"},
+	"fr":    {" ", ",", "02/01 15:04:05"},
+	"es":    {".", ",", "02/01 15:04:05"},
+	"it":    {".", ",", "02/01 15:04:05"},
+	"ja":    {",", ".", "01/02 15:04:05"},
+	"zh":    {",", ".", "01/02 15:04:05"},
+}
+
+func parseLocale(name string) (locale, error) {
+	name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		name = name[:i]
+	}
+	if loc, OK := locales[name]; OK {
+		return loc, nil
+	}
+	if i := strings.IndexByte(name, '-'); i > 0 {
+		if loc, OK := locales[name[:i]]; OK {
+			return loc, nil
+		}
+	}
+	return locale{}, fmt.Errorf("unknown locale %q", name)
+}
+
+func (loc locale) number(v interface{}) (string, bool) {
+	var s string
+	switch n := v.(type) {
+	case int:
+		s = strconv.FormatInt(int64(n), 10)
+	case int8:
+		s = strconv.FormatInt(int64(n), 10)
+	case int16:
+		s = strconv.FormatInt(int64(n), 10)
+	case int32:
+		s = strconv.FormatInt(int64(n), 10)
+	case int64:
+		s = strconv.FormatInt(n, 10)
+	case uint:
+		s = strconv.FormatUint(uint64(n), 10)
+	case uint8:
+		s = strconv.FormatUint(uint64(n), 10)
+	case uint16:
+		s = strconv.FormatUint(uint64(n), 10)
+	case uint32:
+		s = strconv.FormatUint(uint64(n), 10)
+	case uint64:
+		s = strconv.FormatUint(n, 10)
+	case float32:
+		s = strconv.FormatFloat(float64(n), 'f', -1, 32)
+	case float64:
+		s = strconv.FormatFloat(n, 'f', -1, 64)
+	default:
+		return "", false
+	}
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], loc.decimal+s[i+1:]
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(loc.thousands)
+		}
+		b.WriteRune(c)
+	}
+	b.WriteString(frac)
+	return b.String(), true
+}
+
+func (l *Logger) SetLocale(name string) error {
+	loc, err := parseLocale(name)
+	if name != "" && err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.locale = name
+	if c, OK := l.enc.(consoleEncoder); OK {
+		c.locale = loc
+		l.enc = c
+	}
+	return nil
+}
@@ -1,45 +1,61 @@
 package logger
 
 import (
-	"compress/gzip"
-	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"io/ioutil"
 	"log"
 	"os"
-	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 var (
-	Trace, Info, Waring, Error *log.Logger
-	configs                    = map[level]*loggerConfig{
+	Trace, Info, Waring, Error *Logger
+	configs                    = map[Level]*loggerConfig{
 		TRACE:   defaultConfig(TRACE),
 		INFO:    defaultConfig(INFO),
 		WARNING: defaultConfig(WARNING),
 		ERROR:   defaultConfig(ERROR),
 	}
-	reg = regexp.MustCompile(`log\.(.+)\.((?i)out|format|prefix|reserve|filesuffix|compress)=(.+)`)
+	allConfig       = &loggerConfig{fileSuffix: defaultTimeFormat, compress: defaultCompress, shared: defaultShared, sharedTags: strings.Split(defaultSharedTag, ",")}
+	moduleOverrides = map[string][]override{}
+	moduleLevels    = map[string]Level{}
+	levelReg        = regexp.MustCompile(`^log\.level(?:\.([^=]+))?=(.+)$`)
+	moduleLevelReg  = regexp.MustCompile(`^log\.([^=]+)\.(?i:level)=(.+)$`)
+	reg             = regexp.MustCompile(`^log\.([^=]+)\.((?i)out|format|prefix|reserve|filesuffix|compress|maxsize|utc|shared|sharedtag|buffer|layout|units|maxbackups|maxtotalsize|sample|ratelimit|id|ttl|buildinfo|locale|fieldnames|journal|route|emptyout|seq)=(.+)$`)
 )
 
 const (
-	defaultFlag       = log.LstdFlags | log.Lshortfile
-	defaultCompress   = true
-	defaultReserve    = 0
-	defaultTimeFormat = "20060102"
+	defaultFlag         = log.LstdFlags | log.Lshortfile
+	defaultCompress     = true
+	defaultReserve      = 0
+	defaultTimeFormat   = "20060102"
+	defaultMaxSize      = 0
+	defaultMaxBackups   = 0
+	defaultMaxTotalSize = 0
+	defaultUTC          = false
+	defaultBuffer       = 0
+	defaultShared       = SharedOff
+	defaultSharedTag    = "hostname"
+	allTarget           = "all"
+	emptyOutStderr      = "stderr"
+	emptyOutDiscard     = "discard"
+	defaultEmptyOut     = emptyOutStderr
 )
 
 func init() {
-	b, e := os.ReadFile("log.properties")
-	if e != nil && !os.IsNotExist(e) {
-		panic(e)
+	resolveConfigs()
+	if err := Validate(); err != nil {
+		fmt.Printf("Invalid log configuration:\n%s\n", err)
 	}
-	parseConfigs(b)
+	createLoggers()
+}
+
+func createLoggers() {
 	for _, config := range configs {
 		switch config.level {
 		case TRACE:
@@ -62,53 +78,265 @@ func parseConfigs(contents []byte) {
 		if line == "" {
 			continue
 		}
+		if res := levelReg.FindStringSubmatch(line); len(res) > 0 {
+			parseLevel(res[1], res[2])
+			continue
+		}
+		if res := moduleLevelReg.FindStringSubmatch(line); len(res) > 0 {
+			parseModuleLevel(res[1], res[2])
+			continue
+		}
 		res := reg.FindStringSubmatch(line)
 		if len(res) == 0 {
 			continue
 		}
-		config, OK := configs[level(strings.ToUpper(res[1]))]
-		if !OK {
+		target := res[1]
+		if strings.EqualFold(target, allTarget) {
+			allConfig.set(res[2], res[3])
 			continue
 		}
-		switch strings.ToLower(res[2]) {
-		case "out":
-			if writers := parseOutWriter(strings.Split(res[3], ",")); len(writers) > 0 {
-				config.out = writers
-			}
-		case "format":
-			if flag, err := strconv.Atoi(res[3]); err == nil && flag < log.Lmsgprefix<<1 {
-				config.flag = flag
-			} else {
-				fmt.Printf("Invalid format flag [%s],use default:[%d]\n", res[3], defaultFlag)
-			}
-		case "prefix":
-			config.prefix = res[3]
-		case "reserve":
-			if reserve, err := strconv.Atoi(res[3]); err != nil {
-				fmt.Printf("Invalid format reserve [%s],use default:[%d]\n", res[3], defaultReserve)
-			} else if reserve > 0 {
-				config.reserve = reserve
-			}
-		case "filesuffix":
-			config.fileSuffix = res[3]
-		case "compress":
-			if compress, e := strconv.ParseBool(res[3]); e == nil {
-				config.compress = compress
-			} else {
-				fmt.Printf("Invalid format compress [%s],use default:[%t]\n", res[3], defaultCompress)
+		if i := strings.LastIndexByte(target, '.'); i > 0 {
+			lvl := Level(strings.ToUpper(target[i+1:]))
+			if _, OK := configs[lvl]; OK {
+				moduleOverrides[target[:i]] = append(moduleOverrides[target[:i]], override{lvl, res[2], res[3]})
 			}
-		default:
-			fmt.Println("Invalid key :", res[2])
+			continue
+		}
+		if config, OK := configs[Level(strings.ToUpper(target))]; OK {
+			config.set(res[2], res[3])
 		}
 	}
 	return
 }
 
+type override struct {
+	level      Level
+	key, value string
+}
+
+func (l *loggerConfig) set(key, value string) {
+	switch strings.ToLower(key) {
+	case "out":
+		l.out = parseOutWriter(splitList(value))
+	case "format":
+		if flag, err := strconv.Atoi(value); err == nil && flag < log.Lmsgprefix<<1 {
+			l.layout = layoutFromFlags(flag)
+		} else if _, err = newEncoder(value, UnitsDefault); err == nil {
+			l.encoding = strings.ToLower(value)
+		} else {
+			fmt.Printf("Invalid format flag [%s],use default:[%d]\n", value, defaultFlag)
+		}
+	case "layout":
+		if layout, e := parseLayout(value); e == nil {
+			l.layout = layout
+		} else {
+			fmt.Printf("Invalid format layout [%s],use default:[%s]\n", value, layoutFromFlags(defaultFlag))
+		}
+	case "units":
+		if units, OK := parseUnits(value); OK {
+			l.units = units
+		} else {
+			fmt.Printf("Invalid format units [%s],use default:[%s]\n", value, "default")
+		}
+	case "prefix":
+		l.prefix = value
+	case "reserve":
+		if reserve, err := strconv.Atoi(value); err != nil {
+			fmt.Printf("Invalid format reserve [%s],use default:[%d]\n", value, defaultReserve)
+		} else if reserve > 0 {
+			l.reserve = reserve
+		}
+	case "filesuffix":
+		l.fileSuffix = value
+	case "compress":
+		if compress, e := strconv.ParseBool(value); e == nil {
+			l.compress = compress
+		} else {
+			fmt.Printf("Invalid format compress [%s],use default:[%t]\n", value, defaultCompress)
+		}
+	case "utc":
+		if utc, e := strconv.ParseBool(value); e == nil {
+			l.utc = utc
+		} else {
+			fmt.Printf("Invalid format utc [%s],use default:[%t]\n", value, defaultUTC)
+		}
+	case "shared":
+		if mode := strings.ToLower(value); mode == SharedAuto {
+			l.shared = mode
+		} else if on, e := strconv.ParseBool(mode); e == nil {
+			l.shared = strconv.FormatBool(on)
+		} else {
+			fmt.Printf("Invalid format shared [%s],use default:[%s]\n", value, defaultShared)
+		}
+	case "sharedtag":
+		l.sharedTags = strings.Split(value, ",")
+	case "buffer":
+		if size, e := parseSize(value); e == nil {
+			l.buffer = int(size)
+		} else {
+			fmt.Printf("Invalid format buffer [%s],use default:[%d]\n", value, defaultBuffer)
+		}
+	case "maxbackups":
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			l.maxBackups = n
+		} else {
+			fmt.Printf("Invalid format maxbackups [%s],use default:[%d]\n", value, defaultMaxBackups)
+		}
+	case "maxtotalsize":
+		if size, e := parseSize(value); e == nil {
+			l.maxTotalSize = size
+		} else {
+			fmt.Printf("Invalid format maxtotalsize [%s],use default:[%d]\n", value, defaultMaxTotalSize)
+		}
+	case "sample":
+		if keep, every, e := parseSample(value); e == nil {
+			l.sampleKeep, l.sampleEvery = keep, every
+		} else {
+			fmt.Printf("Invalid format sample [%s],use default:[%s]\n", value, "1/1")
+		}
+	case "ratelimit":
+		if limit, per, e := parseRateLimit(value); e == nil {
+			l.rateLimit, l.ratePer = limit, per
+		} else {
+			fmt.Printf("Invalid format ratelimit [%s],use default:[%s]\n", value, "unlimited")
+		}
+	case "id":
+		if g, e := newIDGenerator(value); e == nil {
+			l.idGen = g
+		} else {
+			fmt.Printf("Invalid format id [%s],use default:[%s]\n", value, "none")
+		}
+	case "ttl":
+		if _, e := parseTTL(value); e == nil {
+			l.ttl = value
+		} else {
+			fmt.Printf("Invalid format ttl [%s],use default:[%s]\n", value, "none")
+		}
+	case "buildinfo":
+		if on, e := strconv.ParseBool(value); e == nil {
+			l.buildInfo = on
+		} else {
+			fmt.Printf("Invalid format buildinfo [%s],use default:[%t]\n", value, false)
+		}
+	case "locale":
+		if _, e := parseLocale(value); e == nil {
+			l.locale = value
+		} else {
+			fmt.Printf("Invalid format locale [%s],use default:[%s]\n", value, "none")
+		}
+	case "fieldnames":
+		if names, e := parseFieldNames(value); e == nil {
+			l.fieldNames = names
+		} else {
+			fmt.Printf("Invalid format fieldnames [%s],use default:[%s]\n", value, "none")
+		}
+	case "journal":
+		if on, e := strconv.ParseBool(value); e == nil {
+			l.journal = on
+		} else {
+			fmt.Printf("Invalid format journal [%s],use default:[%t]\n", value, false)
+		}
+	case "route":
+		if field, template, e := parseRoute(value); e == nil {
+			l.route = [2]string{field, template}
+		} else {
+			fmt.Printf("Invalid format route [%s],use default:[%s]\n", value, "none")
+		}
+	case "emptyout":
+		if mode := strings.ToLower(value); mode == emptyOutStderr || mode == emptyOutDiscard {
+			l.emptyOut = mode
+		} else {
+			fmt.Printf("Invalid format emptyout [%s],use default:[%s]\n", value, defaultEmptyOut)
+		}
+	case "seq":
+		if on, e := strconv.ParseBool(value); e == nil {
+			l.seq = on
+		} else {
+			fmt.Printf("Invalid format seq [%s],use default:[%t]\n", value, false)
+		}
+	case "maxsize":
+		if size, e := parseSize(value); e == nil {
+			l.maxSize = size
+		} else {
+			fmt.Printf("Invalid format maxsize [%s],use default:[%d]\n", value, defaultMaxSize)
+		}
+	default:
+		fmt.Println("Invalid key :", key)
+	}
+}
+
+func parseLevel(pkg, value string) {
+	lvl := Level(strings.ToUpper(strings.TrimSpace(value)))
+	if lvl.severity() < 0 {
+		fmt.Printf("Invalid level [%s],use default:[%s]\n", value, defaultLevel)
+		return
+	}
+	if pkg = strings.TrimSpace(pkg); pkg == "" {
+		SetLevel(lvl)
+	} else {
+		SetPackageLevel(pkg, lvl)
+	}
+}
+
+// parseModuleLevel handles log.<module>.level, the verbosity of the loggers
+// GetLogger returns for that module.
+func parseModuleLevel(module, value string) {
+	if _, OK := configs[Level(strings.ToUpper(module))]; OK || strings.EqualFold(module, allTarget) {
+		fmt.Println("Invalid key :", module+".level")
+		return
+	}
+	lvl := Level(strings.ToUpper(strings.TrimSpace(value)))
+	if lvl.severity() < 0 {
+		fmt.Printf("Invalid level [%s] for %s,use default:[%s]\n", value, module, defaultLevel)
+		return
+	}
+	moduleLevels[module] = lvl
+}
+
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"G", 1 << 30}, {"MB", 1 << 20}, {"M", 1 << 20}, {"KB", 1 << 10}, {"K", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.size
+			break
+		}
+	}
+	size, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return size * unit, nil
+}
+
+func splitList(s string) []string {
+	items := strings.Split(s, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
 func parseOutWriter(outs []string) []string {
 	var writers []string
 	for _, out := range outs {
+		if out == "" {
+			continue
+		}
 		switch o := strings.ToLower(out); o {
-		case "stdin", "stdout", "stderr", "discard":
+		case "stdin", "stdout", "stderr", "discard", measureOut:
 			writers = append(writers, o)
 		default:
 			writers = append(writers, out)
@@ -118,255 +346,214 @@ func parseOutWriter(outs []string) []string {
 }
 
 type loggerConfig struct {
-	level              level
+	level              Level
+	name               string
 	out                []string
 	prefix, fileSuffix string
-	reserve, flag      int
-	compress           bool
+	encoding, shared   string
+	sharedTags         []string
+	maxSize            int64
+	maxBackups         int
+	sampleKeep         uint64
+	sampleEvery        uint64
+	rateLimit          int
+	idGen              IDGenerator
+	ttl                string
+	buildInfo          bool
+	locale             string
+	fieldNames         map[string]string
+	journal            bool
+	route              [2]string
+	emptyOut           string
+	seq                bool
+	flushEach          bool
+	ratePer            time.Duration
+	maxTotalSize       int64
+	reserve            int
+	layout             Layout
+	units              Units
+	buffer             int
+	compress, utc      bool
+}
+
+func (l *loggerConfig) clone() *loggerConfig {
+	c := *l
+	c.out = append([]string(nil), l.out...)
+	c.sharedTags = append([]string(nil), l.sharedTags...)
+	return &c
 }
 
-func (l *loggerConfig) Create() *log.Logger {
-	ws := make([]io.Writer, 0)
-	for _, o := range l.out {
-		if w, OK := defaultWriter[o]; OK {
-			ws = append(ws, w)
+func (l *loggerConfig) Create() *Logger {
+	out := l.out
+	if len(out) == 0 && l.route[0] == "" && len(allConfig.out) == 0 {
+		out = []string{emptyOut(l.measureName(), l.emptyOut)}
+	}
+	sinks := make([]*sink, 0, len(out))
+	for _, o := range out {
+		if o == measureOut {
+			sinks = append(sinks, &sink{name: o, out: o, w: NewMeasureWriter(l.measureName()), names: l.fieldNames})
+			continue
+		}
+		sinks = append(sinks, &sink{name: o, out: o, w: l.openOut(o), names: l.fieldNames})
+	}
+	if l.route[0] != "" {
+		if r, e := l.openRoute(); e == nil {
+			sinks = append(sinks, &sink{name: "route", out: "route:" + l.route[0] + ":" + l.route[1], w: r, names: l.fieldNames})
 		} else {
-			if l, e := newLogWriter(o, reserve(l.reserve), timeFormat(l.fileSuffix), compress(l.compress)); e == nil {
-				ws = append(ws, l)
-			} else {
-				panic(e)
-			}
+			reportError("open route %s failed: %s", l.route[1], e)
 		}
 	}
-	var out io.Writer
-	if l := len(ws); l == 1 {
-		out = ws[0]
-	} else if l > 1 {
-		out = io.MultiWriter(ws...)
+	for _, o := range allConfig.out {
+		if !contains(l.out, o) {
+			sinks = append(sinks, &sink{name: allTarget, out: o, w: allConfig.openOut(o), names: l.fieldNames})
+		}
 	}
-	if l.prefix != "" {
-		l.prefix = fmt.Sprintf("[%s] ", l.prefix)
+	for _, s := range sinks {
+		if l.seq {
+			s.seq = s.sequence()
+		}
+		s.flush = l.flushEach
 	}
-	return log.New(out, l.prefix, l.flag)
-}
-
-var defaultWriter = map[string]io.Writer{
-	"stdin":   os.Stdin,
-	"stdout":  os.Stdout,
-	"stderr":  os.Stderr,
-	"discard": ioutil.Discard,
-}
-
-type level string
-
-const (
-	TRACE   level = "TRACE"
-	INFO    level = "INFO"
-	WARNING level = "WARNING"
-	ERROR   level = "ERROR"
-)
-
-func defaultConfig(level level) *loggerConfig {
-	return &loggerConfig{
-		level:      level,
-		out:        []string{"stdout"},
-		prefix:     string(level),
-		flag:       defaultFlag,
-		compress:   defaultCompress,
-		reserve:    defaultReserve,
-		fileSuffix: defaultTimeFormat,
+	enc, err := newEncoder(l.encoding, l.units)
+	if err != nil {
+		panic(err)
 	}
-}
-
-//writer
-
-const compressSuffix = ".gz"
-
-type option func(*logWriter)
-
-func reserve(day int) option {
-	return func(l *logWriter) {
-		l.reserve = day
+	layout := l.layout
+	if l.utc {
+		layout.UTC = true
 	}
-}
-
-func compress(compressed bool) option {
-	return func(l *logWriter) {
-		l.compressed = compressed
+	logger := newLogger(l.level, sinks, l.prefix, layout, enc)
+	logger.name = l.name
+	logger.units = l.units
+	logger.idGen = l.idGen
+	logger.ttl = l.ttl
+	logger.buildInfo = l.buildInfo
+	logger.emptyOut = l.emptyOut
+	if l.locale != "" {
+		_ = logger.SetLocale(l.locale)
 	}
-}
-
-func timeFormat(format string) option {
-	return func(l *logWriter) {
-		l.timeFormat = format
+	if l.sampleEvery > 0 {
+		logger.SetSampling(l.sampleKeep, l.sampleEvery)
 	}
-}
-
-func newLogWriter(logPath string, options ...option) (*logWriter, error) {
-	dir, name := filepath.Split(logPath)
-	var err error
-	if err := os.MkdirAll(dir, os.ModeDir|0744); err != nil {
-		return nil, err
-	}
-	ext := filepath.Ext(name)
-	l := &logWriter{
-		dir:          dir,
-		name:         strings.TrimSuffix(name, ext) + ".",
-		ext:          ext,
-		linkFileName: logPath,
-	}
-	for _, o := range options {
-		o(l)
+	if l.rateLimit > 0 {
+		logger.SetRateLimit(l.rateLimit, l.ratePer)
 	}
-	_, err = l.openOrNew()
-	return l, err
+	return logger
 }
 
-type logWriter struct {
-	dir, name, ext, suffix string
-	linkFileName           string
-	file                   *os.File
-
-	reserve    int
-	compressed bool
-	timeFormat string
+func (l *loggerConfig) measureName() string {
+	return measureName(l.name, l.level)
 }
 
-func (l *logWriter) Write(p []byte) (int, error) {
-	f, err := l.openOrNew()
-	if err != nil {
-		fmt.Printf("write fail, msg(%s)\n", err)
-		return 0, err
+func measureName(name string, level Level) string {
+	if name == "" {
+		return string(level)
 	}
-	return f.Write(p)
+	return name + "." + string(level)
 }
 
-func (l *logWriter) Close() error {
-	if l.file == nil {
-		return nil
+// emptyOut picks the writer for a logger left without any: stderr unless
+// emptyout=discard, announced once so the gap is visible.
+func emptyOut(name, mode string) string {
+	if mode == "" {
+		mode = defaultEmptyOut
 	}
-	defer func() { l.file = nil }()
-	return l.file.Close()
+	fmt.Printf("No out configured for %s,use:[%s]\n", name, mode)
+	return mode
 }
 
-func (l *logWriter) deleteFile() {
-	if l.reserve <= 0 {
-		return
+var (
+	outsMu  sync.Mutex
+	outs    = map[string]io.Writer{}
+	outOpts = map[string]string{}
+)
+
+func (l *loggerConfig) openOut(o string) io.Writer {
+	if w, OK := defaultWriter[o]; OK {
+		return w
 	}
-	minDate, _ := time.Parse(l.timeFormat, l.suffix)
-	minDate = minDate.Add(time.Hour * time.Duration(-l.reserve*24))
-	_ = filepath.Walk(l.dir, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			fmt.Printf("open log dir %s failed", l.dir)
-		}
-		if info == nil {
-			return nil
-		}
-		if info.IsDir() && l.dir != path {
-			return fs.SkipDir
+	outsMu.Lock()
+	defer outsMu.Unlock()
+	opts := ""
+	if !isNetworkOut(o) {
+		opts = l.outOptions()
+	}
+	if w, OK := outs[o]; OK {
+		if opts != outOpts[o] {
+			reportError("out %s is already open with %s, ignoring %s for %s", o, outOpts[o], opts, l.measureName())
 		}
-		if info.IsDir() || path == l.linkFileName || path == l.fileName(l.suffix) {
-			return nil
+		return w
+	}
+	var w io.Writer
+	if isNetworkOut(o) {
+		nw, e := newNetWriter(o, l.level)
+		if e != nil {
+			reportError("open out %s failed: %s", o, e)
+			return ioutil.Discard
 		}
-		if t, e := l.timeFromName(info.Name()); e != nil {
-			//fmt.Println(e)
-		} else if t.Before(minDate) {
-			if err = os.Remove(path); err != nil {
-				fmt.Printf("remove file %s failed\n", path)
+		w = nw
+	} else {
+		lw, _ := NewRotatingWriter(o, Reserve(l.reserve), TimeFormat(l.fileSuffix), Compress(l.compress), MaxSize(l.maxSize), MaxBackups(l.maxBackups), MaxTotalSize(l.maxTotalSize), UTC(l.utc), Shared(l.shared, l.sharedTags))
+		w = lw
+		if l.journal {
+			if jw, e := newJournalWriter(lw, o+journalSuffix); e == nil {
+				w = jw
+			} else {
+				reportError("open journal for %s failed: %s", o, e)
 			}
+		} else if l.buffer > 0 {
+			w = newBufferedWriter(lw, l.buffer, defaultFlushInterval)
 		}
-		return nil
-	})
-}
-
-func (l *logWriter) timeFromName(filename string) (time.Time, error) {
-	nameNoPrefix := strings.TrimPrefix(filename, l.name)
-	if filename == nameNoPrefix {
-		return time.Time{}, errors.New("mismatched prefix")
 	}
-	nameNoSuffix := strings.TrimSuffix(nameNoPrefix, compressSuffix)
-	nameNoSuffix = strings.TrimSuffix(nameNoSuffix, l.ext)
-	if nameNoPrefix == nameNoSuffix {
-		return time.Time{}, errors.New("mismatched extension")
-	}
-	return time.Parse(l.timeFormat, nameNoSuffix)
+	register(w)
+	outs[o], outOpts[o] = w, opts
+	return w
 }
 
-func (l *logWriter) openOrNew() (*os.File, error) {
-	suffix := l.timeSuffix()
-	if l.file == nil || l.suffix != suffix {
-		filename := l.fileName(suffix)
-		_, err := os.Stat(filename)
-		if err == nil && l.file == nil {
-			l.file, err = os.OpenFile(filename, os.O_RDWR|os.O_APPEND, 0644)
-		}
-		if err == nil {
-			return l.file, nil
-		}
-		if f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644); err == nil {
-			_ = l.compress()
-			l.file = f
-			l.suffix = suffix
-			go l.deleteFile()
-			if err = os.Remove(l.linkFileName); err == nil || os.IsNotExist(err) {
-				err = os.Link(filename, l.linkFileName)
-			}
-			if err != nil {
-				fmt.Println("rotate log file error:", err)
-			}
-		} else {
-			if l.file == nil {
-				return nil, fmt.Errorf("can't open new logfile: %s", err)
-			} else {
-				fmt.Println("can't open new logfile: ", err)
-				return f, nil
-			}
-		}
-	}
-	return l.file, nil
+// outOptions describes the settings an out is opened with. Outs are shared
+// by path, so only the first opener's settings take effect.
+func (l *loggerConfig) outOptions() string {
+	return fmt.Sprintf("reserve=%d filesuffix=%s compress=%t maxsize=%d maxbackups=%d maxtotalsize=%d utc=%t shared=%s sharedtag=%s buffer=%d journal=%t",
+		l.reserve, l.fileSuffix, l.compress, l.maxSize, l.maxBackups, l.maxTotalSize, l.utc, l.shared, strings.Join(l.sharedTags, ","), l.buffer, l.journal)
 }
 
-func (l *logWriter) compress() (err error) {
-	defer l.file.Close()
-	if l.file == nil || !l.compressed {
-		return nil
+func (l *loggerConfig) openRoute() (*fieldRouter, error) {
+	key := "route:" + l.route[0] + ":" + l.route[1]
+	outsMu.Lock()
+	defer outsMu.Unlock()
+	if w, OK := outs[key]; OK {
+		return w.(*fieldRouter), nil
 	}
-	fi, err := l.file.Stat()
+	r, err := newFieldRouter(l.route[0], l.route[1], defaultRouteOpen, Reserve(l.reserve), TimeFormat(l.fileSuffix), Compress(l.compress), MaxSize(l.maxSize), MaxBackups(l.maxBackups), MaxTotalSize(l.maxTotalSize), UTC(l.utc))
 	if err != nil {
-		return fmt.Errorf("failed to stat log file: %v", err)
-	}
-	src := l.file.Name()
-	dst := src + compressSuffix
-	gzf, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fi.Mode())
-	if err != nil {
-		return fmt.Errorf("failed to open compressed log file: %v", err)
-	}
-	defer gzf.Close()
-	gz := gzip.NewWriter(gzf)
-	defer func() {
-		if err != nil {
-			_ = os.Remove(dst)
-			err = fmt.Errorf("failed to compress log file: %v", err)
-		}
-	}()
-	if _, err = l.file.Seek(0, 0); err == nil {
-		if _, err = io.Copy(gz, l.file); err == nil {
-			if err = gz.Close(); err == nil {
-				if err = gzf.Close(); err == nil {
-					if err = l.file.Close(); err == nil {
-						err = os.Remove(src)
-					}
-				}
-			}
-		}
+		return nil, err
 	}
-	return err
+	register(r)
+	outs[key] = r
+	return r, nil
 }
 
-func (l *logWriter) fileName(suffix string) string {
-	return filepath.Join(l.dir, fmt.Sprintf("%s%s%s", l.name, suffix, l.ext))
+var defaultWriter = map[string]io.Writer{
+	"stdin":   os.Stdin,
+	"stdout":  os.Stdout,
+	"stderr":  os.Stderr,
+	"discard": ioutil.Discard,
 }
 
-func (l *logWriter) timeSuffix() string {
-	return time.Now().Format(l.timeFormat)
+func defaultConfig(level Level) *loggerConfig {
+	return &loggerConfig{
+		level:      level,
+		out:        []string{"stdout"},
+		prefix:     string(level),
+		layout:     layoutFromFlags(defaultFlag),
+		compress:   defaultCompress,
+		reserve:    defaultReserve,
+		fileSuffix: defaultTimeFormat,
+		encoding:   textFormat,
+		maxSize:    defaultMaxSize,
+		utc:        defaultUTC,
+		shared:     defaultShared,
+		buffer:     defaultBuffer,
+		sharedTags: strings.Split(defaultSharedTag, ","),
+	}
 }
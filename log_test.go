@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestEmptyOut(t *testing.T) {
+	cases := []struct {
+		name  string
+		lines map[string]string
+		want  string
+	}{
+		{"only separators", map[string]string{"out": ","}, emptyOutStderr},
+		{"discard", map[string]string{"out": ",", "emptyout": "discard"}, emptyOutDiscard},
+		{"unknown mode", map[string]string{"out": ",", "emptyout": "syslog"}, emptyOutStderr},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := defaultConfig(INFO)
+			for k, v := range c.lines {
+				config.set(k, v)
+			}
+			l := config.Create()
+			if len(l.sinks) != 1 || l.sinks[0].name != c.want || l.sinks[0].w != defaultWriter[c.want] {
+				t.Fatalf("sinks = %+v, want a single %s sink", l.sinks, c.want)
+			}
+		})
+	}
+}
+
+func TestSetOutputNil(t *testing.T) {
+	for _, mode := range []string{"", emptyOutDiscard} {
+		config := defaultConfig(INFO)
+		config.out = []string{"stdout"}
+		config.emptyOut = mode
+		l := config.Create()
+		l.SetOutput(nil)
+		want := mode
+		if want == "" {
+			want = emptyOutStderr
+		}
+		if len(l.sinks) != 1 || l.sinks[0].w != defaultWriter[want] {
+			t.Fatalf("emptyout %q: sinks = %+v, want %s", mode, l.sinks, want)
+		}
+	}
+	l := newLogger(INFO, nil, "INFO", Layout{}, textEncoder{})
+	l.SetOutput(nil)
+	if l.Writer() != os.Stderr {
+		t.Fatalf("Writer() = %v, want stderr", l.Writer())
+	}
+	l.SetOutput(ioutil.Discard)
+	if l.Writer() != ioutil.Discard {
+		t.Fatalf("Writer() = %v, want the writer set last", l.Writer())
+	}
+}
+
+func TestModuleLevelConfig(t *testing.T) {
+	global := GetLevel()
+	defer func() {
+		SetLevel(global)
+		delete(moduleLevels, "catalog")
+	}()
+	SetLevel(INFO)
+	parseConfigs([]byte("log.catalog.level=ERROR\n"))
+	if GetLevel() != INFO {
+		t.Fatalf("global level = %s, want INFO", GetLevel())
+	}
+	if moduleLevels["catalog"] != ERROR {
+		t.Fatalf("catalog level = %q, want ERROR", moduleLevels["catalog"])
+	}
+	parseConfigs([]byte("xlog.level=ERROR\n"))
+	if GetLevel() != INFO {
+		t.Fatalf("unanchored key changed the global level to %s", GetLevel())
+	}
+}
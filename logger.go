@@ -0,0 +1,362 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Logger  string
+	File    string
+	Line    int
+	Message string
+	Fields  []Field
+}
+
+type Logger struct {
+	*core
+	fields *fieldList
+	ctx    context.Context
+}
+
+type core struct {
+	mu          sync.Mutex
+	name        string
+	level       Level
+	minSeverity int32
+	muted       int32
+	prefix      string
+	layout      Layout
+	sinks       []*sink
+	enc         encoder
+	throttle    *throttle
+	idGen       IDGenerator
+	ttl         string
+	buildInfo   bool
+	locale      string
+	aggs        *aggregators
+	units       Units
+	emptyOut    string
+}
+
+func newLogger(level Level, sinks []*sink, prefix string, layout Layout, enc encoder) *Logger {
+	return &Logger{core: &core{level: level, minSeverity: -1, sinks: sinks, prefix: prefix, layout: layout, enc: enc}}
+}
+
+func (l *Logger) With(args ...interface{}) *Logger {
+	own := make([]Field, 0, len(args)/2+1)
+	for i := 0; i < len(args); i++ {
+		switch a := args[i].(type) {
+		case Field:
+			own = append(own, a)
+		case string:
+			if i+1 < len(args) {
+				own = append(own, Field{Key: a, Value: args[i+1]})
+				i++
+			} else {
+				own = append(own, Field{Key: badKey, Value: a})
+			}
+		default:
+			own = append(own, Field{Key: badKey, Value: a})
+		}
+	}
+	return &Logger{core: l.core, fields: l.fields.with(own), ctx: l.ctx}
+}
+
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	return &Logger{core: l.core, fields: l.fields, ctx: ctx}
+}
+
+func (l *Logger) Output(calldepth int, s string) error {
+	if !l.enabled(calldepth) {
+		return nil
+	}
+	return l.output(calldepth+1, s)
+}
+
+func (l *Logger) output(calldepth int, s string) error {
+	e := Entry{Time: now(), Level: l.level, Logger: l.name, Message: s, Fields: l.fields.fields()}
+	stampRequestID(l.ctx, &e)
+	l.mu.Lock()
+	needCaller := l.enc.needCaller(l.layout)
+	l.mu.Unlock()
+	if needCaller {
+		var ok bool
+		if _, e.File, e.Line, ok = runtime.Caller(calldepth); !ok {
+			e.File, e.Line = "???", 0
+		}
+	}
+	return l.write(&e)
+}
+
+func (l *Logger) write(e *Entry) error {
+	if l.aggregated(e) {
+		return nil
+	}
+	return l.emit(e)
+}
+
+func (l *Logger) emit(e *Entry) error {
+	if l.throttled(e) {
+		return nil
+	}
+	if err := validateSchema(e); err != nil {
+		return err
+	}
+	l.stampID(e)
+	l.stampTTL(e)
+	l.stampBuildInfo(e)
+	stampIdentity(e)
+	states, OK := l.quotaAllow(e)
+	if !OK {
+		return nil
+	}
+	runHooks(e, true)
+	n, err := l.writeBounded(e)
+	if len(states) > 0 {
+		l.quotaUsed(e, states, n)
+	}
+	runHooks(e, false)
+	return err
+}
+
+type pending struct {
+	s   *sink
+	ent *Entry
+	p   *[]byte
+}
+
+// writeSinks encodes under l.mu but does the sink I/O without it, so a slow
+// sink only holds up callers writing to that same sink.
+func (l *Logger) writeSinks(e *Entry) (int, error) {
+	l.mu.Lock()
+	writes := make([]pending, 0, len(l.sinks))
+	bufs := make([]*[]byte, 0, len(l.sinks))
+	var shared *[]byte
+	for _, s := range l.sinks {
+		ent, enc := e, l.enc
+		if len(s.transforms) > 0 || s.names != nil || s.seq != nil {
+			se, OK := s.apply(*e)
+			if !OK {
+				continue
+			}
+			if s.seq != nil {
+				se = s.stampSeq(se)
+			}
+			if s.names != nil {
+				se, enc = s.rename(se), enc.withNames(s.names)
+			}
+			ent = &se
+		} else if shared != nil {
+			writes = append(writes, pending{s, e, shared})
+			continue
+		}
+		p := bufPool.Get().(*[]byte)
+		*p = enc.encode(grow(*p, enc.sizeHint(ent, l.prefix)), ent, l.prefix, l.layout)
+		recordEncoded(len(*p))
+		if ent == e {
+			shared = p
+		}
+		bufs = append(bufs, p)
+		writes = append(writes, pending{s, ent, p})
+	}
+	l.mu.Unlock()
+	defer func() {
+		for _, p := range bufs {
+			bufPool.Put(p)
+		}
+	}()
+	var err, late error
+	attempted, failed, size := 0, 0, 0
+	for _, w := range writes {
+		if !w.s.acquire(l.ctx) {
+			late = l.ctx.Err()
+			continue
+		}
+		attempted++
+		if len(*w.p) > size {
+			size = len(*w.p)
+		}
+		if _, werr := w.s.write(w.ent, *w.p); werr != nil {
+			failed++
+			err = werr
+		}
+		if f, OK := w.s.w.(flusher); OK && w.s.flush {
+			_ = f.Flush()
+		}
+		w.s.release()
+	}
+	if late != nil {
+		if err == nil {
+			err = late
+		}
+		fallback.write(e, late)
+	} else if attempted > 0 && failed == attempted {
+		fallback.write(e, err)
+	}
+	return size, err
+}
+
+func (l *Logger) Print(v ...interface{}) {
+	if l.enabled(1) {
+		_ = l.output(2, fmt.Sprint(v...))
+	}
+}
+
+func (l *Logger) Printf(format string, v ...interface{}) {
+	if l.enabled(1) {
+		_ = l.output(2, fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *Logger) Println(v ...interface{}) {
+	if l.enabled(1) {
+		_ = l.output(2, fmt.Sprintln(v...))
+	}
+}
+
+func (l *Logger) Fatal(v ...interface{}) {
+	if l.enabled(1) {
+		_ = l.output(2, fmt.Sprint(v...))
+	}
+	flushBeforeExit()
+	os.Exit(1)
+}
+
+func (l *Logger) Fatalf(format string, v ...interface{}) {
+	if l.enabled(1) {
+		_ = l.output(2, fmt.Sprintf(format, v...))
+	}
+	flushBeforeExit()
+	os.Exit(1)
+}
+
+func (l *Logger) Fatalln(v ...interface{}) {
+	if l.enabled(1) {
+		_ = l.output(2, fmt.Sprintln(v...))
+	}
+	flushBeforeExit()
+	os.Exit(1)
+}
+
+func (l *Logger) Panic(v ...interface{}) {
+	s := fmt.Sprint(v...)
+	if l.enabled(1) {
+		_ = l.output(2, s)
+	}
+	flushBeforeExit()
+	panic(s)
+}
+
+func (l *Logger) Panicf(format string, v ...interface{}) {
+	s := fmt.Sprintf(format, v...)
+	if l.enabled(1) {
+		_ = l.output(2, s)
+	}
+	flushBeforeExit()
+	panic(s)
+}
+
+func (l *Logger) Panicln(v ...interface{}) {
+	s := fmt.Sprintln(v...)
+	if l.enabled(1) {
+		_ = l.output(2, s)
+	}
+	flushBeforeExit()
+	panic(s)
+}
+
+func (l *Logger) SetFormat(format string) error {
+	enc, err := newEncoder(format, UnitsDefault)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.enc = enc.withUnits(l.units)
+	locale := l.locale
+	l.mu.Unlock()
+	if locale != "" {
+		return l.SetLocale(locale)
+	}
+	return nil
+}
+
+func (l *Logger) SetUnits(units Units) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.units = units
+	l.enc = l.enc.withUnits(units)
+}
+
+func (l *Logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w == nil {
+		mode := emptyOut(measureName(l.name, l.level), l.emptyOut)
+		l.sinks = []*sink{{name: mode, w: defaultWriter[mode]}}
+		return
+	}
+	l.sinks = []*sink{{name: "output", w: w}}
+}
+
+func (l *Logger) Writer() io.Writer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch len(l.sinks) {
+	case 0:
+		return nil
+	case 1:
+		return l.sinks[0].w
+	}
+	ws := make(fanout, 0, len(l.sinks))
+	for _, s := range l.sinks {
+		ws = append(ws, s.w)
+	}
+	return ws
+}
+
+func (l *Logger) SetPrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prefix = prefix
+}
+
+func (l *Logger) Prefix() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.prefix
+}
+
+func (l *Logger) SetFlags(flag int) {
+	l.SetLayout(layoutFromFlags(flag))
+}
+
+func (l *Logger) Flags() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.layout.Flags()
+}
+
+func (l *Logger) SetLayout(layout Layout) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.layout = layout
+}
+
+func (l *Logger) Layout() Layout {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.layout
+}
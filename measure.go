@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const measureOut = "measure"
+
+type Measurement struct {
+	Name           string
+	Entries, Bytes int64
+	Since          time.Time
+	Elapsed        time.Duration
+}
+
+func (m Measurement) BytesPerSecond() float64 {
+	if m.Elapsed <= 0 {
+		return 0
+	}
+	return float64(m.Bytes) / m.Elapsed.Seconds()
+}
+
+func (m Measurement) EntriesPerSecond() float64 {
+	if m.Elapsed <= 0 {
+		return 0
+	}
+	return float64(m.Entries) / m.Elapsed.Seconds()
+}
+
+type MeasureWriter struct {
+	name           string
+	entries, bytes int64
+	since          time.Time
+}
+
+var (
+	measuresMu sync.Mutex
+	measures   = map[string]*MeasureWriter{}
+)
+
+func NewMeasureWriter(name string) *MeasureWriter {
+	measuresMu.Lock()
+	defer measuresMu.Unlock()
+	if m, OK := measures[name]; OK {
+		return m
+	}
+	m := &MeasureWriter{name: name, since: time.Now()}
+	measures[name] = m
+	return m
+}
+
+func (m *MeasureWriter) Write(p []byte) (int, error) {
+	atomic.AddInt64(&m.entries, 1)
+	atomic.AddInt64(&m.bytes, int64(len(p)))
+	return len(p), nil
+}
+
+func (m *MeasureWriter) Measurement() Measurement {
+	return Measurement{
+		Name:    m.name,
+		Entries: atomic.LoadInt64(&m.entries),
+		Bytes:   atomic.LoadInt64(&m.bytes),
+		Since:   m.since,
+		Elapsed: time.Since(m.since),
+	}
+}
+
+func Measurements() []Measurement {
+	measuresMu.Lock()
+	defer measuresMu.Unlock()
+	res := make([]Measurement, 0, len(measures))
+	for _, m := range measures {
+		res = append(res, m.Measurement())
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+const sizeBuckets = 20
+
+type SizeBucket struct {
+	UpTo  int
+	Count int64
+}
+
+type Metrics struct {
+	Encoded      int64
+	EncodedBytes int64
+	EncodedSizes []SizeBucket
+
+	RetentionDeleted    int64
+	RetentionCompressed int64
+	RetentionMoved      int64
+	RetentionFreedBytes int64
+
+	HookDropped int64
+
+	Sequences map[string]uint64
+}
+
+var retentionStats retentionCounters
+
+type retentionCounters struct {
+	deleted, compressed, moved, freed int64
+}
+
+func (c *retentionCounters) add(action RetentionAction, freed int64) {
+	switch action {
+	case RetentionDelete:
+		atomic.AddInt64(&c.deleted, 1)
+	case RetentionCompress:
+		atomic.AddInt64(&c.compressed, 1)
+	case RetentionMove:
+		atomic.AddInt64(&c.moved, 1)
+	}
+	atomic.AddInt64(&c.freed, freed)
+}
+
+var encodeStats struct {
+	count, bytes int64
+	buckets      [sizeBuckets]int64
+}
+
+func recordEncoded(n int) {
+	atomic.AddInt64(&encodeStats.count, 1)
+	atomic.AddInt64(&encodeStats.bytes, int64(n))
+	b := 0
+	if n > 1 {
+		b = bits.Len(uint(n - 1))
+	}
+	if b >= sizeBuckets {
+		b = sizeBuckets - 1
+	}
+	atomic.AddInt64(&encodeStats.buckets[b], 1)
+}
+
+func ReadMetrics() Metrics {
+	m := Metrics{
+		Encoded:      atomic.LoadInt64(&encodeStats.count),
+		EncodedBytes: atomic.LoadInt64(&encodeStats.bytes),
+
+		RetentionDeleted:    atomic.LoadInt64(&retentionStats.deleted),
+		RetentionCompressed: atomic.LoadInt64(&retentionStats.compressed),
+		RetentionMoved:      atomic.LoadInt64(&retentionStats.moved),
+		RetentionFreedBytes: atomic.LoadInt64(&retentionStats.freed),
+
+		HookDropped: atomic.LoadInt64(&hookDropped),
+
+		Sequences: sequenceMetrics(),
+	}
+	for b := range encodeStats.buckets {
+		if c := atomic.LoadInt64(&encodeStats.buckets[b]); c > 0 {
+			m.EncodedSizes = append(m.EncodedSizes, SizeBucket{UpTo: 1 << b, Count: c})
+		}
+	}
+	return m
+}
+
+func estimateSize(e *Entry, prefix string, fixed, perField int) int {
+	n := fixed + len(prefix) + 2*len(e.Logger) + len(e.File) + len(e.Message)
+	for _, f := range e.Fields {
+		n += perField + len(f.Key)
+		if s, OK := f.Value.(string); OK {
+			n += len(s)
+		}
+	}
+	return n
+}
+
+func grow(buf []byte, n int) []byte {
+	if cap(buf) >= n {
+		return buf[:0]
+	}
+	return make([]byte, 0, n)
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"io"
+	"path"
+	"sort"
+	"sync"
+)
+
+type Loggers struct {
+	Name                        string
+	Trace, Info, Warning, Error *Logger
+}
+
+var (
+	modulesMu sync.Mutex
+	modules   = map[string]*Loggers{}
+)
+
+func GetLogger(name string) *Loggers {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	if m, OK := modules[name]; OK {
+		return m
+	}
+	m := &Loggers{Name: name}
+	for lvl, config := range configs {
+		c := config.clone()
+		c.name = name
+		for _, o := range moduleOverrides[name] {
+			if o.level == lvl {
+				c.set(o.key, o.value)
+			}
+		}
+		switch lvl {
+		case TRACE:
+			m.Trace = c.Create()
+		case INFO:
+			m.Info = c.Create()
+		case WARNING:
+			m.Warning = c.Create()
+		case ERROR:
+			m.Error = c.Create()
+		}
+	}
+	if lvl, OK := moduleLevels[name]; OK {
+		m.SetLevel(lvl)
+	}
+	modules[name] = m
+	return m
+}
+
+func (m *Loggers) each(fn func(*Logger)) {
+	for _, l := range []*Logger{m.Trace, m.Info, m.Warning, m.Error} {
+		fn(l)
+	}
+}
+
+func (m *Loggers) SetLevel(lvl Level) {
+	m.each(func(l *Logger) { l.SetLevel(lvl) })
+}
+
+func (m *Loggers) AddSink(name string, w io.Writer, transforms ...Transform) {
+	m.each(func(l *Logger) { l.AddSink(name, w, transforms...) })
+}
+
+func (m *Loggers) Mute() {
+	m.each((*Logger).Mute)
+}
+
+func (m *Loggers) Unmute() {
+	m.each((*Logger).Unmute)
+}
+
+type LoggerSet []*Loggers
+
+func Match(pattern string) LoggerSet {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	var set LoggerSet
+	for name, m := range modules {
+		if OK, _ := path.Match(pattern, name); OK {
+			set = append(set, m)
+		}
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i].Name < set[j].Name })
+	return set
+}
+
+func (s LoggerSet) SetLevel(lvl Level) {
+	for _, m := range s {
+		m.SetLevel(lvl)
+	}
+}
+
+func (s LoggerSet) AddSink(name string, w io.Writer, transforms ...Transform) {
+	for _, m := range s {
+		m.AddSink(name, w, transforms...)
+	}
+}
+
+func (s LoggerSet) Mute() {
+	for _, m := range s {
+		m.Mute()
+	}
+}
+
+func (s LoggerSet) Unmute() {
+	for _, m := range s {
+		m.Unmute()
+	}
+}
+
+func (s LoggerSet) Names() []string {
+	names := make([]string, 0, len(s))
+	for _, m := range s {
+		names = append(names, m.Name)
+	}
+	return names
+}
@@ -0,0 +1,38 @@
+package logger
+
+import "strconv"
+
+const errorsField = "errors"
+
+// Multi logs err as one entry. Errors built with errors.Join, or any error
+// exposing Unwrap() []error, are flattened into an array field instead of
+// one concatenated message.
+func (l *Logger) Multi(err error) {
+	if err == nil || !l.enabled(1) {
+		return
+	}
+	errs := flattenErrors(err)
+	if len(errs) <= 1 {
+		_ = l.output(2, err.Error())
+		return
+	}
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	_ = l.With(errorsField, msgs).output(2, strconv.Itoa(len(errs))+" errors occurred")
+}
+
+func flattenErrors(err error) []error {
+	j, OK := err.(interface{ Unwrap() []error })
+	if !OK {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range j.Unwrap() {
+		if e != nil {
+			errs = append(errs, flattenErrors(e)...)
+		}
+	}
+	return errs
+}
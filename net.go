@@ -0,0 +1,271 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	minBackoff   = 100 * time.Millisecond
+	maxBackoff   = 30 * time.Second
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 5 * time.Second
+	netBatchSize = 64 << 10
+)
+
+var errReconnecting = errors.New("network writer is reconnecting")
+
+func isNetworkOut(out string) bool {
+	u, err := url.Parse(out)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "tcp", "udp", "syslog", "syslog+tcp", "syslog+udp":
+		return u.Host != ""
+	}
+	return false
+}
+
+func newNetWriter(out string, lvl Level) (*netWriter, error) {
+	w, err := parseNetOut(out, lvl)
+	if err == nil && (w.gzip || w.deflate != nil) {
+		w.stop = make(chan struct{})
+		go w.loop(defaultFlushInterval)
+	}
+	return w, err
+}
+
+// parseNetOut checks a network out and its options without dialling or
+// starting the batch loop, so validation has nothing to clean up.
+func parseNetOut(out string, lvl Level) (*netWriter, error) {
+	u, err := url.Parse(out)
+	if err != nil {
+		return nil, err
+	}
+	w := &netWriter{addr: u.Host, backoff: minBackoff}
+	switch c := strings.ToLower(u.Query().Get("compress")); c {
+	case "", "none":
+	case "gzip":
+		w.gzip = true
+	case "deflate":
+		w.deflate = &deflateFramer{}
+		if path := u.Query().Get("dict"); path != "" {
+			if w.deflate.dict, err = os.ReadFile(path); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression %q for %s", c, u.Redacted())
+	}
+	switch u.Scheme {
+	case "tcp", "udp":
+		w.network = u.Scheme
+	case "syslog", "syslog+udp", "syslog+tcp":
+		w.network = "udp"
+		if u.Scheme == "syslog+tcp" {
+			w.network = "tcp"
+		}
+		if _, _, e := net.SplitHostPort(u.Host); e != nil {
+			w.addr = net.JoinHostPort(u.Host, "514")
+		}
+		w.syslog = true
+		w.priority = syslogUser | syslogSeverity(lvl)
+		w.tag = strings.TrimPrefix(u.Path, "/")
+		if w.tag == "" {
+			w.tag = filepath.Base(os.Args[0])
+		}
+		w.host, _ = os.Hostname()
+	default:
+		return nil, fmt.Errorf("unsupported network out %q", out)
+	}
+	if (w.gzip || w.deflate != nil) && w.network != "tcp" {
+		return nil, fmt.Errorf("compression needs a stream transport: %s", u.Redacted())
+	}
+	return w, nil
+}
+
+const syslogUser = 1 << 3
+
+func syslogSeverity(lvl Level) int {
+	switch lvl {
+	case ERROR:
+		return 3
+	case WARNING:
+		return 4
+	case INFO:
+		return 6
+	default:
+		return 7
+	}
+}
+
+type netWriter struct {
+	mu            sync.Mutex
+	network, addr string
+	conn          net.Conn
+	gzip          bool
+	gz            *gzip.Writer
+	deflate       *deflateFramer
+	backoff       time.Duration
+	nextDial      time.Time
+	batch, frame  []byte
+	stop          chan struct{}
+	once          sync.Once
+
+	syslog    bool
+	priority  int
+	tag, host string
+}
+
+func (w *netWriter) Write(p []byte) (int, error) {
+	return w.write(p, w.priority)
+}
+
+// writeEntry takes the syslog severity from the entry, since one out can be
+// shared by loggers of every level.
+func (w *netWriter) writeEntry(e *Entry, p []byte) (int, error) {
+	return w.write(p, syslogUser|syslogSeverity(e.Level))
+}
+
+// Compressed outs batch entries and ship a batch once it reaches
+// netBatchSize, every second, or on Flush, so the compressor sees whole
+// batches rather than single lines.
+func (w *netWriter) write(p []byte, priority int) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	msg := p
+	if w.syslog {
+		msg = []byte(fmt.Sprintf("<%d>%s %s %s[%d]: %s", priority, time.Now().Format(time.Stamp), w.host, w.tag, os.Getpid(), strings.TrimSuffix(string(p), "\n")))
+		if w.network == "tcp" {
+			msg = append(msg, '\n')
+		}
+	}
+	if w.stop == nil {
+		if err := w.send(msg); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	w.batch = append(w.batch, msg...)
+	if len(w.batch) >= netBatchSize {
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *netWriter) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.Flush(); err != nil {
+				reportError("send batch to %s failed: %s", w.addr, err)
+			}
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+func (w *netWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+// flush sends the pending batch. A batch that cannot be sent is dropped,
+// the connection is redialled with backoff for the next one.
+func (w *netWriter) flush() error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+	err := w.send(w.batch)
+	w.batch = w.batch[:0]
+	return err
+}
+
+func (w *netWriter) send(msg []byte) error {
+	if err := w.connect(); err != nil {
+		return err
+	}
+	_ = w.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	err := w.sendCompressed(msg)
+	if err != nil {
+		_ = w.conn.Close()
+		w.conn, w.gz = nil, nil
+		w.nextDial = time.Now().Add(w.backoff)
+	}
+	return err
+}
+
+func (w *netWriter) sendCompressed(msg []byte) error {
+	if w.deflate != nil {
+		frame, err := w.deflate.frame(w.frame[:0], msg)
+		if err != nil {
+			return err
+		}
+		w.frame, msg = frame, frame
+	}
+	if w.gz == nil {
+		_, err := w.conn.Write(msg)
+		return err
+	}
+	if _, err := w.gz.Write(msg); err != nil {
+		return err
+	}
+	return w.gz.Flush()
+}
+
+func (w *netWriter) connect() error {
+	if w.conn != nil {
+		return nil
+	}
+	if time.Now().Before(w.nextDial) {
+		return errReconnecting
+	}
+	conn, err := net.DialTimeout(w.network, w.addr, dialTimeout)
+	if err != nil {
+		w.nextDial = time.Now().Add(w.backoff)
+		if w.backoff *= 2; w.backoff > maxBackoff {
+			w.backoff = maxBackoff
+		}
+		return err
+	}
+	w.conn, w.backoff = conn, minBackoff
+	if w.gzip {
+		w.gz = gzip.NewWriter(conn)
+	}
+	return nil
+}
+
+func (w *netWriter) Close() error {
+	if w.stop != nil {
+		w.once.Do(func() { close(w.stop) })
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.flush()
+	if w.conn == nil {
+		return err
+	}
+	defer func() { w.conn, w.gz = nil, nil }()
+	if w.gz != nil {
+		_ = w.gz.Close()
+	}
+	if e := w.conn.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
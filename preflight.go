@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+func Validate() error {
+	seen := map[string]bool{}
+	var outs []string
+	collect := func(o string) {
+		if _, OK := defaultWriter[o]; !OK && o != measureOut && !seen[o] {
+			seen[o] = true
+			outs = append(outs, o)
+		}
+	}
+	for _, config := range configs {
+		for _, o := range config.out {
+			collect(o)
+		}
+	}
+	for _, o := range allConfig.out {
+		collect(o)
+	}
+	for _, overrides := range moduleOverrides {
+		for _, o := range overrides {
+			if o.key == "out" {
+				for _, out := range parseOutWriter(splitList(o.value)) {
+					collect(out)
+				}
+			}
+		}
+	}
+	sort.Strings(outs)
+	var errs []error
+	for _, o := range outs {
+		if err := checkOut(o); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkOut(o string) error {
+	if isNetworkOut(o) {
+		if _, err := parseNetOut(o, INFO); err != nil {
+			return fmt.Errorf("out %s: %v", o, err)
+		}
+		return nil
+	}
+	dir, name := filepath.Split(o)
+	if name == "" {
+		return fmt.Errorf("out %s: missing file name", o)
+	}
+	if dir == "" {
+		dir = "."
+	}
+	if err := checkWritableDir(dir); err != nil {
+		return fmt.Errorf("out %s: %v", o, err)
+	}
+	return nil
+}
+
+func checkWritableDir(dir string) error {
+	p, err := existingAncestor(dir)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", p)
+	}
+	f, err := os.CreateTemp(p, ".logger-check-*")
+	if err != nil {
+		return fmt.Errorf("directory %s is not writable: %v", p, err)
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
+
+func existingAncestor(dir string) (string, error) {
+	p := filepath.Clean(dir)
+	for {
+		_, err := os.Stat(p)
+		if err == nil {
+			return p, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return "", err
+		}
+		p = parent
+	}
+}
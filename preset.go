@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	productionDir       = "log"
+	productionReserve   = 14
+	productionMaxSize   = 100 << 20
+	productionMaxTotal  = 10 << 30
+	productionBuffer    = 256 << 10
+	productionTimestamp = "20060102"
+)
+
+// Presets rewrite the level configs and recreate the global loggers.
+// Module loggers obtained earlier from GetLogger keep their old setup,
+// so a preset should be applied before any GetLogger call.
+func applyPreset(fn func(*loggerConfig), global ...func()) {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	for _, config := range configs {
+		fn(config)
+	}
+	for _, g := range global {
+		g()
+	}
+	createLoggers()
+	modules = map[string]*Loggers{}
+}
+
+// Serverless writes JSON to stdout only. Outs, routes and journals from
+// log.all.*, the level configs and module overrides are dropped, so no
+// file writer is opened.
+func Serverless() {
+	applyPreset(func(c *loggerConfig) {
+		c.out = []string{"stdout"}
+		c.route = [2]string{}
+		c.journal = false
+		c.encoding = jsonFormat
+		c.buffer = 0
+		c.flushEach = false
+		c.layout.UTC = true
+	}, dropFileOutputs)
+}
+
+// dropFileOutputs clears the outs that live outside the level configs:
+// log.all.out and out, route and journal module overrides.
+func dropFileOutputs() {
+	allConfig.out = nil
+	for name, overrides := range moduleOverrides {
+		kept := overrides[:0]
+		for _, o := range overrides {
+			if k := strings.ToLower(o.key); k != "out" && k != "route" && k != "journal" {
+				kept = append(kept, o)
+			}
+		}
+		moduleOverrides[name] = kept
+	}
+}
+
+func Dev() {
+	applyPreset(func(c *loggerConfig) {
+		c.out = []string{"stderr"}
+		c.encoding = consoleFormat
+		c.buffer = 0
+		c.flushEach = false
+		c.layout.Caller = ShortCaller
+	})
+	SetLevel(TRACE)
+}
+
+// Production buffers file writes; ERROR entries flush the buffer right away
+// so they survive a crash, and Fatal/Panic flush everything before leaving.
+func Production(appName string) {
+	out := filepath.Join(productionDir, appName+".log")
+	applyPreset(func(c *loggerConfig) {
+		c.out = []string{out}
+		if c.level == ERROR {
+			c.out = append(c.out, "stderr")
+		}
+		c.encoding = jsonFormat
+		c.fileSuffix = productionTimestamp
+		c.reserve = productionReserve
+		c.maxSize = productionMaxSize
+		c.maxTotalSize = productionMaxTotal
+		c.buffer = productionBuffer
+		c.flushEach = c.level == ERROR
+		c.compress = true
+	})
+	SetLevel(INFO)
+}
+
+func Invoke(ctx context.Context, fn func(context.Context) error) error {
+	err := fn(ctx)
+	if e := Flush(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	TenantField         = "tenant"
+	defaultQuotaSample  = 100
+	quotaDayFormat      = "20060102"
+	quotaReportInterval = time.Minute
+)
+
+type QuotaMode int
+
+const (
+	QuotaSample QuotaMode = iota
+	QuotaSummary
+)
+
+type Quota struct {
+	Bytes, Entries int64
+	Mode           QuotaMode
+	SampleEvery    int64
+}
+
+type quotaState struct {
+	key        string
+	quota      Quota
+	day        string
+	bytes      int64
+	entries    int64
+	exceeded   bool
+	seen       int64
+	suppressed int64
+	logger     *Logger
+}
+
+var (
+	quotaMu     sync.Mutex
+	quotaCount  int32 // len(quotas), read without quotaMu on the hot path
+	quotas      = map[string]*quotaState{}
+	quotaReport sync.Once
+)
+
+func SetLoggerQuota(name string, q Quota) {
+	setQuota("logger:"+name, q)
+}
+
+func SetTenantQuota(tenant string, q Quota) {
+	setQuota("tenant:"+tenant, q)
+}
+
+func setQuota(key string, q Quota) {
+	if q.SampleEvery <= 0 {
+		q.SampleEvery = defaultQuotaSample
+	}
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+	defer func() { atomic.StoreInt32(&quotaCount, int32(len(quotas))) }()
+	if q.Bytes <= 0 && q.Entries <= 0 {
+		delete(quotas, key)
+		return
+	}
+	quotas[key] = &quotaState{key: key, quota: q}
+	quotaReport.Do(func() { go reportQuotas() })
+}
+
+func quotaKeys(e *Entry) []string {
+	keys := []string{"logger:" + e.Logger}
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == TenantField {
+			keys = append(keys, fmt.Sprintf("tenant:%v", e.Fields[i].Value))
+			break
+		}
+	}
+	return keys
+}
+
+func (l *Logger) quotaAllow(e *Entry) (states []*quotaState, allowed bool) {
+	if atomic.LoadInt32(&quotaCount) == 0 {
+		return nil, true
+	}
+	quotaMu.Lock()
+	defer quotaMu.Unlock()
+	if len(quotas) == 0 {
+		return nil, true
+	}
+	day := e.Time.Format(quotaDayFormat)
+	allowed = true
+	for _, key := range quotaKeys(e) {
+		s, OK := quotas[key]
+		if !OK {
+			continue
+		}
+		if s.day != day {
+			s.day, s.bytes, s.entries, s.exceeded, s.seen = day, 0, 0, false, 0
+		}
+		s.logger = l
+		states = append(states, s)
+		if !s.exceeded {
+			continue
+		}
+		s.seen++
+		if s.quota.Mode == QuotaSummary || (s.seen-1)%s.quota.SampleEvery != 0 {
+			s.suppressed++
+			allowed = false
+		}
+	}
+	return states, allowed
+}
+
+func (l *Logger) quotaUsed(e *Entry, states []*quotaState, n int) {
+	var warnings []string
+	quotaMu.Lock()
+	for _, s := range states {
+		s.bytes += int64(n)
+		s.entries++
+		if !s.exceeded && (s.quota.Bytes > 0 && s.bytes >= s.quota.Bytes || s.quota.Entries > 0 && s.entries >= s.quota.Entries) {
+			s.exceeded = true
+			warnings = append(warnings, fmt.Sprintf("quota exceeded for %s: %d bytes, %d entries today", s.key, s.bytes, s.entries))
+		}
+	}
+	quotaMu.Unlock()
+	for _, w := range warnings {
+		_, _ = l.writeSinks(&Entry{Time: e.Time, Level: l.level, Logger: l.name, File: e.File, Line: e.Line, Message: w})
+	}
+}
+
+func reportQuotas() {
+	ticker := time.NewTicker(quotaReportInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		type report struct {
+			l   *Logger
+			msg string
+		}
+		var reports []report
+		quotaMu.Lock()
+		for _, s := range quotas {
+			if s.suppressed > 0 && s.logger != nil {
+				reports = append(reports, report{s.logger, fmt.Sprintf("%d entries suppressed by quota for %s", s.suppressed, s.key)})
+				s.suppressed = 0
+			}
+		}
+		quotaMu.Unlock()
+		for _, r := range reports {
+			_, _ = r.l.writeSinks(&Entry{Time: now, Level: r.l.level, Logger: r.l.name, File: "???", Message: r.msg})
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+const RequestIDField = "request_id"
+
+type requestIDKey struct{}
+
+var requestIDFunc atomic.Value // func(context.Context) (string, bool)
+
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// SetRequestIDFunc plugs in an extractor for platform contexts, e.g.
+// lambdacontext.FromContext, consulted when no ID was set explicitly.
+func SetRequestIDFunc(fn func(context.Context) (string, bool)) {
+	requestIDFunc.Store(fn)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	if id, OK := ctx.Value(requestIDKey{}).(string); OK && id != "" {
+		return id, true
+	}
+	if fn, _ := requestIDFunc.Load().(func(context.Context) (string, bool)); fn != nil {
+		return fn(ctx)
+	}
+	return "", false
+}
+
+func stampRequestID(ctx context.Context, e *Entry) {
+	if id, OK := RequestIDFromContext(ctx); OK {
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], Field{Key: RequestIDField, Value: id})
+	}
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"flag"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultConfigFile = "log.properties"
+	configFlag        = "log.config"
+	configEnv         = "LOG_CONFIG"
+	envPrefix         = "LOG_"
+)
+
+// Configuration is resolved in layers, later layers win:
+//
+//	defaults < properties file (-log.config, LOG_CONFIG or ./log.properties) < LOG_* environment variables
+//
+// Environment keys map to property keys by lower-casing and replacing "_" with ".",
+// e.g. LOG_INFO_OUT=log/info.log is log.info.out=log/info.log.
+func resolveConfigs() {
+	b, e := os.ReadFile(configFile())
+	if e != nil && !os.IsNotExist(e) {
+		panic(e)
+	}
+	parseConfigs(b)
+	parseConfigs(envConfigs(os.Environ()))
+}
+
+func configFile() string {
+	if flag.Lookup(configFlag) == nil {
+		flag.String(configFlag, defaultConfigFile, "path of the log properties file")
+	}
+	for i, arg := range os.Args[1:] {
+		name := strings.TrimLeft(arg, "-")
+		if name == arg || len(arg)-len(name) > 2 {
+			continue
+		}
+		if strings.HasPrefix(name, configFlag+"=") {
+			return strings.TrimPrefix(name, configFlag+"=")
+		}
+		if name == configFlag && i+2 < len(os.Args) {
+			return os.Args[i+2]
+		}
+	}
+	if path := os.Getenv(configEnv); path != "" {
+		return path
+	}
+	return defaultConfigFile
+}
+
+func envConfigs(environ []string) []byte {
+	var lines []string
+	for _, kv := range environ {
+		i := strings.IndexByte(kv, '=')
+		if i < 0 || !strings.HasPrefix(kv[:i], envPrefix) || kv[:i] == configEnv {
+			continue
+		}
+		key := strings.ToLower(strings.ReplaceAll(kv[:i], "_", "."))
+		lines = append(lines, key+"="+kv[i+1:])
+	}
+	sort.Strings(lines)
+	return []byte(strings.Join(lines, "\n"))
+}
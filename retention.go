@@ -0,0 +1,244 @@
+package logger
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const maxRetentionScans = 2
+
+// Retention runs are coalesced per directory: while a scan of a directory
+// is pending or running, further requests for it only mark it dirty, and a
+// single follow-up scan picks them up. At most maxRetentionScans
+// directories are walked at the same time. Finished compressions request
+// another run, since files being compressed are skipped by the scan.
+var (
+	retentionMu    sync.Mutex
+	retentionDirs  = map[string]*retentionScanner{}
+	retentionSlots = make(chan struct{}, maxRetentionScans)
+)
+
+type retentionScanner struct {
+	running bool
+	pending map[*RotatingWriter]bool
+}
+
+func scheduleRetention(l *RotatingWriter) {
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+	s, OK := retentionDirs[l.dir]
+	if !OK {
+		s = &retentionScanner{pending: map[*RotatingWriter]bool{}}
+		retentionDirs[l.dir] = s
+	}
+	s.pending[l] = true
+	if !s.running {
+		s.running = true
+		go s.run()
+	}
+}
+
+func (s *retentionScanner) run() {
+	for {
+		retentionMu.Lock()
+		pending := s.pending
+		if len(pending) == 0 {
+			s.running = false
+			retentionMu.Unlock()
+			return
+		}
+		s.pending = map[*RotatingWriter]bool{}
+		retentionMu.Unlock()
+		retentionSlots <- struct{}{}
+		for l := range pending {
+			l.mu.Lock()
+			suffix, current := l.suffix, ""
+			if l.file != nil {
+				current = l.file.Name()
+			}
+			l.mu.Unlock()
+			l.deleteFile(suffix, current)
+		}
+		<-retentionSlots
+	}
+}
+
+type RetentionAction int
+
+const (
+	RetentionDelete RetentionAction = iota
+	RetentionCompress
+	RetentionMove
+)
+
+type RetentionDecision struct {
+	File   LogFile
+	Action RetentionAction
+	Target string
+	Policy string
+	Reason string
+}
+
+func (a RetentionAction) String() string {
+	switch a {
+	case RetentionDelete:
+		return "delete"
+	case RetentionCompress:
+		return "compress"
+	case RetentionMove:
+		return "move"
+	}
+	return "unknown"
+}
+
+func (a RetentionAction) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+type RetentionEvent struct {
+	Time   time.Time       `json:"time"`
+	File   string          `json:"file"`
+	Action RetentionAction `json:"action"`
+	Target string          `json:"target,omitempty"`
+	Policy string          `json:"policy"`
+	Reason string          `json:"reason,omitempty"`
+	Freed  int64           `json:"freed_bytes"`
+	Error  string          `json:"error,omitempty"`
+}
+
+var retentionHooks atomic.Value // []func(RetentionEvent)
+
+func OnRetention(fn func(RetentionEvent)) {
+	retentionMu.Lock()
+	defer retentionMu.Unlock()
+	hooks, _ := retentionHooks.Load().([]func(RetentionEvent))
+	retentionHooks.Store(append(hooks[:len(hooks):len(hooks)], fn))
+}
+
+func recordRetention(d RetentionDecision, freed int64, err error) {
+	ev := RetentionEvent{Time: time.Now(), File: d.File.Path, Action: d.Action, Target: d.Target, Policy: d.Policy, Reason: d.Reason, Freed: freed}
+	if ev.Policy == "" {
+		ev.Policy = "custom"
+	}
+	if err != nil {
+		ev.Error, ev.Freed = err.Error(), 0
+	} else {
+		retentionStats.add(d.Action, freed)
+	}
+	reportEvent("retention", ev)
+	hooks, _ := retentionHooks.Load().([]func(RetentionEvent))
+	for _, fn := range hooks {
+		fn(ev)
+	}
+}
+
+// RetentionPolicy picks files from a writer's inventory to act on. now is
+// the period of the file being written; files marked Current are never
+// touched, whatever the policy returns.
+type RetentionPolicy interface {
+	Apply(now time.Time, files []LogFile) []RetentionDecision
+}
+
+type RetentionFunc func(now time.Time, files []LogFile) []RetentionDecision
+
+func (f RetentionFunc) Apply(now time.Time, files []LogFile) []RetentionDecision {
+	return f(now, files)
+}
+
+func AgePolicy(maxAge time.Duration) RetentionPolicy {
+	return RetentionFunc(func(now time.Time, files []LogFile) []RetentionDecision {
+		var res []RetentionDecision
+		for _, f := range files {
+			if !f.Current && f.Period.Before(now.Add(-maxAge)) {
+				res = append(res, RetentionDecision{File: f, Policy: "age", Reason: "older than " + maxAge.String()})
+			}
+		}
+		return res
+	})
+}
+
+func CountPolicy(maxBackups int) RetentionPolicy {
+	return RetentionFunc(func(_ time.Time, files []LogFile) []RetentionDecision {
+		var res []RetentionDecision
+		n := 0
+		for _, f := range newestFirst(files) {
+			if f.Current {
+				continue
+			}
+			if n++; n > maxBackups {
+				res = append(res, RetentionDecision{File: f, Policy: "count", Reason: "more than " + strconv.Itoa(maxBackups) + " backups"})
+			}
+		}
+		return res
+	})
+}
+
+func SizePolicy(maxTotalSize int64) RetentionPolicy {
+	return RetentionFunc(func(_ time.Time, files []LogFile) []RetentionDecision {
+		var res []RetentionDecision
+		var total int64
+		for _, f := range files {
+			if f.Current {
+				total += f.Size
+			}
+		}
+		for _, f := range newestFirst(files) {
+			if f.Current {
+				continue
+			}
+			if total += f.Size; total > maxTotalSize {
+				res = append(res, RetentionDecision{File: f, Policy: "size", Reason: "total exceeds " + strconv.FormatInt(maxTotalSize, 10) + " bytes"})
+			}
+		}
+		return res
+	})
+}
+
+// AnyOf acts on a file as soon as one policy selects it, AllOf only when
+// every policy does. The decision of the last selecting policy is used, so
+// AllOf(filters..., action) reads naturally.
+func AnyOf(policies ...RetentionPolicy) RetentionPolicy {
+	return combine(policies, 1)
+}
+
+func AllOf(policies ...RetentionPolicy) RetentionPolicy {
+	return combine(policies, len(policies))
+}
+
+func combine(policies []RetentionPolicy, need int) RetentionPolicy {
+	return RetentionFunc(func(now time.Time, files []LogFile) []RetentionDecision {
+		votes := map[string]int{}
+		decisions := map[string]RetentionDecision{}
+		var order []string
+		for _, p := range policies {
+			for _, d := range p.Apply(now, files) {
+				if _, OK := decisions[d.File.Path]; !OK {
+					order = append(order, d.File.Path)
+				}
+				decisions[d.File.Path] = d
+				votes[d.File.Path]++
+			}
+		}
+		var res []RetentionDecision
+		for _, path := range order {
+			if votes[path] >= need {
+				res = append(res, decisions[path])
+			}
+		}
+		return res
+	})
+}
+
+func newestFirst(files []LogFile) []LogFile {
+	sorted := append([]LogFile(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].Period.Equal(sorted[j].Period) {
+			return sorted[i].Period.After(sorted[j].Period)
+		}
+		return sorted[i].Index > sorted[j].Index
+	})
+	return sorted
+}
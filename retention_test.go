@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var retentionNow = time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+func retentionFiles() []LogFile {
+	day := func(n int) time.Time { return retentionNow.AddDate(0, 0, -n) }
+	return []LogFile{
+		{Path: "current", Period: day(0), Size: 10, Current: true},
+		{Path: "d1", Period: day(1), Size: 10},
+		{Path: "d2", Period: day(2), Size: 10},
+		{Path: "d3.1", Period: day(3), Index: 1, Size: 10},
+		{Path: "d3", Period: day(3), Size: 10},
+	}
+}
+
+var moveAll = RetentionFunc(func(_ time.Time, files []LogFile) []RetentionDecision {
+	var res []RetentionDecision
+	for _, f := range files {
+		res = append(res, RetentionDecision{File: f, Action: RetentionMove, Target: "archive/" + f.Path, Policy: "move"})
+	}
+	return res
+})
+
+func TestRetentionPolicies(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy RetentionPolicy
+		want   []string
+	}{
+		{"age", AgePolicy(36 * time.Hour), []string{"d2", "d3.1", "d3"}},
+		{"count", CountPolicy(2), []string{"d3.1", "d3"}},
+		{"count orders indexes", CountPolicy(3), []string{"d3"}},
+		{"size counts current", SizePolicy(25), []string{"d2", "d3.1", "d3"}},
+		{"size at limit", SizePolicy(30), []string{"d3.1", "d3"}},
+		{"any of", AnyOf(AgePolicy(60*time.Hour), CountPolicy(2)), []string{"d3.1", "d3"}},
+		{"any of union", AnyOf(AgePolicy(36*time.Hour), CountPolicy(3)), []string{"d2", "d3.1", "d3"}},
+		{"all of", AllOf(AgePolicy(36*time.Hour), CountPolicy(3)), []string{"d3"}},
+		{"all of none", AllOf(AgePolicy(24*time.Hour*30), CountPolicy(1)), nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got []string
+			for _, d := range c.policy.Apply(retentionNow, retentionFiles()) {
+				got = append(got, d.File.Path)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestAllOfUsesLastDecision(t *testing.T) {
+	res := AllOf(AgePolicy(36*time.Hour), moveAll).Apply(retentionNow, retentionFiles())
+	if len(res) != 3 {
+		t.Fatalf("got %d decisions, want 3", len(res))
+	}
+	for _, d := range res {
+		if d.Action != RetentionMove || d.Target != "archive/"+d.File.Path || d.Policy != "move" {
+			t.Fatalf("decision %+v does not come from the last policy", d)
+		}
+	}
+}
+
+func TestRetentionKeepsCurrent(t *testing.T) {
+	SetDiagnosticOutput(ioutil.Discard)
+	t.Cleanup(func() { SetDiagnosticOutput(os.Stdout) })
+	dir := t.TempDir()
+	deleteAll := RetentionFunc(func(_ time.Time, files []LogFile) []RetentionDecision {
+		var res []RetentionDecision
+		for _, f := range files {
+			res = append(res, RetentionDecision{File: f, Policy: "all"})
+		}
+		return res
+	})
+	w := newRotatingWriter(filepath.Join(dir, "app.log"), Retention(deleteAll))
+	for _, name := range []string{"app.20240101.log", "app.20240102.1.log", "app.20240110.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	current := filepath.Join(dir, "app.20240110.log")
+	w.deleteFile("20240110", current)
+	files, err := w.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path != current {
+		t.Fatalf("files after retention = %+v, want only the current file", files)
+	}
+}
@@ -0,0 +1,211 @@
+package logger
+
+import (
+	"bufio"
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	routeValue       = "{value}"
+	defaultRouteOpen = 128
+	routeBufferSize  = 4 << 10
+)
+
+// fieldRouter splits a sink into one rotating file per value of a field.
+// Only the maxOpen most recently used files are kept open; evicted ones
+// are flushed and closed, and resume appending when written again.
+type fieldRouter struct {
+	mu       sync.Mutex
+	field    string
+	template string
+	options  []WriterOption
+	maxOpen  int
+	lru      *list.List
+	handles  map[string]*list.Element
+	stop     chan struct{}
+	once     sync.Once
+}
+
+type routeHandle struct {
+	value string
+	w     *RotatingWriter
+	buf   *bufio.Writer
+}
+
+func newFieldRouter(field, template string, maxOpen int, options ...WriterOption) (*fieldRouter, error) {
+	if field == "" || !strings.Contains(template, routeValue) {
+		return nil, fmt.Errorf("route needs a field and a path containing %s", routeValue)
+	}
+	if maxOpen <= 0 {
+		maxOpen = defaultRouteOpen
+	}
+	dir := filepath.Dir(template[:strings.Index(template, routeValue)] + "_")
+	if err := checkWritableDir(dir); err != nil {
+		return nil, err
+	}
+	r := &fieldRouter{field: field, template: template, options: options, maxOpen: maxOpen, lru: list.New(), handles: map[string]*list.Element{}, stop: make(chan struct{})}
+	go r.loop(defaultFlushInterval)
+	return r, nil
+}
+
+func (r *fieldRouter) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.flushBuffers()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+func (r *fieldRouter) flushBuffers() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for el := r.lru.Front(); el != nil; el = el.Next() {
+		if h := el.Value.(*routeHandle); h.buf.Buffered() > 0 {
+			_ = h.flush()
+		}
+	}
+}
+
+func (l *Logger) AddRoute(name, field, template string, maxOpen int, options ...WriterOption) error {
+	r, err := newFieldRouter(field, template, maxOpen, options...)
+	if err != nil {
+		return err
+	}
+	register(r)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, &sink{name: name, w: r})
+	return nil
+}
+
+func parseRoute(value string) (field, template string, err error) {
+	kv := strings.SplitN(value, ":", 2)
+	if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || !strings.Contains(kv[1], routeValue) {
+		return "", "", fmt.Errorf("invalid route %q", value)
+	}
+	return strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]), nil
+}
+
+func (r *fieldRouter) value(e *Entry) (string, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == r.field {
+			return fmt.Sprint(e.Fields[i].Value), true
+		}
+	}
+	return "", false
+}
+
+func routeFileName(value string) string {
+	value = strings.Map(func(c rune) rune {
+		if c == '/' || c == '\\' || c == ':' || c < ' ' {
+			return '_'
+		}
+		return c
+	}, value)
+	if value == "" || strings.Trim(value, ".") == "" {
+		return "_"
+	}
+	return value
+}
+
+func (r *fieldRouter) writeEntry(e *Entry, p []byte) (int, error) {
+	value, OK := r.value(e)
+	if !OK {
+		return len(p), nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.handle(value).write(p)
+}
+
+func (r *fieldRouter) handle(value string) *routeHandle {
+	if el, OK := r.handles[value]; OK {
+		r.lru.MoveToFront(el)
+		return el.Value.(*routeHandle)
+	}
+	// The directory was checked once in newFieldRouter; per-value handles
+	// skip the probe and the leftover scan to keep cache misses cheap.
+	w := newRotatingWriter(strings.ReplaceAll(r.template, routeValue, routeFileName(value)), r.options...)
+	w.scanned = true
+	h := &routeHandle{value: value, w: w, buf: bufio.NewWriterSize(w, routeBufferSize)}
+	r.handles[value] = r.lru.PushFront(h)
+	for r.lru.Len() > r.maxOpen {
+		r.evict(r.lru.Back())
+	}
+	return h
+}
+
+func (r *fieldRouter) evict(el *list.Element) {
+	h := r.lru.Remove(el).(*routeHandle)
+	delete(r.handles, h.value)
+	if err := closeRoute(h); err != nil {
+		reportError("close route %s failed: %s", h.value, err)
+	}
+}
+
+func (h *routeHandle) write(p []byte) (int, error) {
+	n, err := h.buf.Write(p)
+	return n, h.recover(err)
+}
+
+func (h *routeHandle) flush() error {
+	return h.recover(h.buf.Flush())
+}
+
+// recover resets the buffer after a failure, since bufio errors are sticky
+// and would otherwise leave the route dead.
+func (h *routeHandle) recover(err error) error {
+	if err != nil {
+		reportError("route %s write failed, %d bytes dropped: %s", h.value, h.buf.Buffered(), err)
+		h.buf.Reset(h.w)
+	}
+	return err
+}
+
+func closeRoute(h *routeHandle) error {
+	return errors.Join(h.flush(), h.w.Close())
+}
+
+// Write only satisfies io.Writer for Flush and Shutdown bookkeeping; entries
+// reach the router through writeEntry, which knows the field value.
+func (r *fieldRouter) Write(p []byte) (int, error) {
+	return 0, errors.New("route writer needs an entry")
+}
+
+func (r *fieldRouter) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs []error
+	for el := r.lru.Front(); el != nil; el = el.Next() {
+		h := el.Value.(*routeHandle)
+		errs = append(errs, h.flush(), h.w.Flush())
+	}
+	return errors.Join(errs...)
+}
+
+func (r *fieldRouter) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs []error
+	for r.lru.Len() > 0 {
+		h := r.lru.Remove(r.lru.Back()).(*routeHandle)
+		delete(r.handles, h.value)
+		errs = append(errs, closeRoute(h))
+	}
+	return errors.Join(errs...)
+}
+
+var _ io.WriteCloser = (*fieldRouter)(nil)
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Kind int
+
+const (
+	AnyKind Kind = iota
+	StringKind
+	IntKind
+	FloatKind
+	BoolKind
+	TimeKind
+	DurationKind
+	ErrorKind
+)
+
+var kindNames = [...]string{"any", "string", "int", "float", "bool", "time", "duration", "error"}
+
+func (k Kind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "unknown"
+}
+
+type FieldSchema struct {
+	Kind     Kind
+	Required bool
+}
+
+type Schema map[string]FieldSchema
+
+type SchemaMode int
+
+const (
+	SchemaWarn SchemaMode = iota
+	SchemaReject
+)
+
+type registeredSchema struct {
+	schema Schema
+	mode   SchemaMode
+}
+
+var (
+	schemasMu sync.Mutex
+	schemas   atomic.Value // map[string]*registeredSchema
+)
+
+func RegisterSchema(name string, schema Schema, mode SchemaMode) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	old, _ := schemas.Load().(map[string]*registeredSchema)
+	m := make(map[string]*registeredSchema, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	if schema == nil {
+		delete(m, name)
+	} else {
+		m[name] = &registeredSchema{schema: schema, mode: mode}
+	}
+	schemas.Store(m)
+}
+
+func validateSchema(e *Entry) error {
+	m, _ := schemas.Load().(map[string]*registeredSchema)
+	rs, OK := m[e.Logger]
+	if !OK {
+		return nil
+	}
+	var problems []string
+	seen := make(map[string]bool, len(e.Fields))
+	for _, f := range e.Fields {
+		seen[f.Key] = true
+		if fs, OK := rs.schema[f.Key]; OK && fs.Kind != AnyKind && kindOf(f.Value) != fs.Kind {
+			problems = append(problems, fmt.Sprintf("field %q is %s, want %s", f.Key, kindOf(f.Value), fs.Kind))
+		}
+	}
+	for key, fs := range rs.schema {
+		if fs.Required && !seen[key] {
+			problems = append(problems, fmt.Sprintf("missing required field %q", key))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	err := errors.New("schema violation: " + strings.Join(problems, "; "))
+	if rs.mode == SchemaReject {
+		reportError("logger %q drop entry, %s", e.Logger, err)
+		return err
+	}
+	reportError("logger %q %s", e.Logger, err)
+	return nil
+}
+
+func kindOf(v interface{}) Kind {
+	switch v.(type) {
+	case bytesValue:
+		return IntKind
+	case durValue:
+		return DurationKind
+	case string:
+		return StringKind
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return IntKind
+	case float32, float64:
+		return FloatKind
+	case bool:
+		return BoolKind
+	case time.Time:
+		return TimeKind
+	case time.Duration:
+		return DurationKind
+	case error:
+		return ErrorKind
+	}
+	return AnyKind
+}
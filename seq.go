@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+const SeqField = "seq"
+
+// Sequence counters are keyed by the writer, so every logger writing to the
+// same out shares one gap-free sequence while different outs never do, even
+// when their sinks share a display name such as "all" or "output".
+var (
+	seqMu     sync.Mutex
+	sequences = map[interface{}]*seqCounter{}
+	seqLabels = map[string]int{}
+)
+
+type seqCounter struct {
+	label string
+	n     uint64
+}
+
+func (s *sink) sequence() *uint64 {
+	label := s.out
+	if label == "" {
+		label = s.name
+	}
+	var key interface{} = s.w
+	if s.w == nil || !reflect.ValueOf(s.w).Comparable() {
+		key = s
+	}
+	seqMu.Lock()
+	defer seqMu.Unlock()
+	if c, OK := sequences[key]; OK {
+		return &c.n
+	}
+	if seqLabels[label]++; seqLabels[label] > 1 {
+		label = fmt.Sprintf("%s#%d", label, seqLabels[label])
+	}
+	c := &seqCounter{label: label}
+	sequences[key] = c
+	return &c.n
+}
+
+func (l *Logger) SetSequence(name string, on bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range l.sinks {
+		if s.name == name {
+			s.seq = nil
+			if on {
+				s.seq = s.sequence()
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("sink %q not found", name)
+}
+
+func (s *sink) stampSeq(e Entry) Entry {
+	n := atomic.AddUint64(s.seq, 1)
+	e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], Field{Key: SeqField, Value: n})
+	return e
+}
+
+func sequenceMetrics() map[string]uint64 {
+	seqMu.Lock()
+	defer seqMu.Unlock()
+	m := make(map[string]uint64, len(sequences))
+	for _, c := range sequences {
+		m[c.label] = atomic.LoadUint64(&c.n)
+	}
+	return m
+}
@@ -0,0 +1,23 @@
+package logger
+
+import "syscall"
+
+var sharedFSTypes = map[uint32]string{
+	0x6969:     "nfs",
+	0x517b:     "smb",
+	0xff534d42: "cifs",
+	0xfe534d42: "smb2",
+	0x00c36400: "ceph",
+	0x65735546: "fuse",
+	0x47504653: "gpfs",
+	0x0bd00bd0: "lustre",
+}
+
+func isSharedDir(dir string) bool {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(dir, &fs); err != nil {
+		return false
+	}
+	_, OK := sharedFSTypes[uint32(fs.Type)]
+	return OK
+}
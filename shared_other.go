@@ -0,0 +1,8 @@
+//go:build !linux
+// +build !linux
+
+package logger
+
+func isSharedDir(string) bool {
+	return false
+}
@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type Transform func(e Entry) (Entry, bool)
+
+type sink struct {
+	name       string
+	out        string
+	w          io.Writer
+	transforms []Transform
+	names      map[string]string
+	seq        *uint64
+	flush      bool
+	once       sync.Once
+	sem        chan struct{}
+}
+
+var (
+	bufPool     = sync.Pool{New: func() interface{} { return new([]byte) }}
+	writerLocks sync.Map // io.Writer -> chan struct{}
+)
+
+// writerLock returns the semaphore shared by every sink writing to w, so
+// loggers sharing an out also share its deadline bound. Writers that cannot
+// be map keys get one of their own.
+func writerLock(w io.Writer) chan struct{} {
+	if w == nil || !reflect.ValueOf(w).Comparable() {
+		return make(chan struct{}, 1)
+	}
+	if v, OK := writerLocks.Load(w); OK {
+		return v.(chan struct{})
+	}
+	v, _ := writerLocks.LoadOrStore(w, make(chan struct{}, 1))
+	return v.(chan struct{})
+}
+
+// acquire takes the sink's writer for one write. Callers with a deadline give
+// up once it passes instead of queueing behind a slow writer.
+func (s *sink) acquire(ctx context.Context) bool {
+	s.once.Do(func() { s.sem = writerLock(s.w) })
+	select {
+	case s.sem <- struct{}{}:
+		return true
+	default:
+	}
+	if ctx != nil {
+		if _, OK := ctx.Deadline(); OK {
+			select {
+			case s.sem <- struct{}{}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+	s.sem <- struct{}{}
+	return true
+}
+
+func (s *sink) release() {
+	<-s.sem
+}
+
+// entryWriter is implemented by writers that need the entry itself, such as
+// routers picking a file by field value or syslog outs picking a severity.
+type entryWriter interface {
+	writeEntry(e *Entry, p []byte) (int, error)
+}
+
+func (s *sink) write(e *Entry, p []byte) (int, error) {
+	if ew, OK := s.w.(entryWriter); OK {
+		return ew.writeEntry(e, p)
+	}
+	return s.w.Write(p)
+}
+
+func (s *sink) apply(e Entry) (Entry, bool) {
+	var OK bool
+	for _, t := range s.transforms {
+		if e, OK = t(e); !OK {
+			return e, false
+		}
+	}
+	return e, true
+}
+
+func (l *Logger) AddSink(name string, w io.Writer, transforms ...Transform) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, &sink{name: name, w: w, transforms: transforms})
+}
+
+func (l *Logger) AddTransform(name string, transforms ...Transform) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range l.sinks {
+		if s.name == name {
+			s.transforms = append(s.transforms, transforms...)
+			return nil
+		}
+	}
+	return fmt.Errorf("sink %q not found", name)
+}
+
+func (l *Logger) SetFieldNames(name string, names map[string]string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range l.sinks {
+		if s.name == name {
+			s.names = names
+			return nil
+		}
+	}
+	return fmt.Errorf("sink %q not found", name)
+}
+
+func (s *sink) rename(e Entry) Entry {
+	fields := make([]Field, len(e.Fields))
+	for i, f := range e.Fields {
+		if name, OK := s.names[f.Key]; OK {
+			f.Key = name
+		}
+		fields[i] = f
+	}
+	e.Fields = fields
+	return e
+}
+
+func parseFieldNames(value string) (map[string]string, error) {
+	names := map[string]string{}
+	for _, pair := range splitList(value) {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+			return nil, fmt.Errorf("invalid field name mapping %q", pair)
+		}
+		names[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return names, nil
+}
+
+func DropFields(keys ...string) Transform {
+	return func(e Entry) (Entry, bool) {
+		fields := make([]Field, 0, len(e.Fields))
+	next:
+		for _, f := range e.Fields {
+			for _, key := range keys {
+				if f.Key == key {
+					continue next
+				}
+			}
+			fields = append(fields, f)
+		}
+		e.Fields = fields
+		return e, true
+	}
+}
+
+func AddFields(fields ...Field) Transform {
+	return func(e Entry) (Entry, bool) {
+		e.Fields = append(append(make([]Field, 0, len(e.Fields)+len(fields)), e.Fields...), fields...)
+		return e, true
+	}
+}
+
+func Filter(fn func(e *Entry) bool) Transform {
+	return func(e Entry) (Entry, bool) {
+		return e, fn(&e)
+	}
+}
+
+type fanout []io.Writer
+
+func (f fanout) Write(p []byte) (int, error) {
+	var err error
+	written := false
+	for _, w := range f {
+		if _, e := w.Write(p); e != nil {
+			err = e
+		} else {
+			written = true
+		}
+	}
+	if !written {
+		return 0, err
+	}
+	return len(p), nil
+}
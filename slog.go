@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+type Handler struct {
+	loggers *Loggers
+	fields  []Field
+	group   string
+}
+
+func NewHandler(m *Loggers) *Handler {
+	if m == nil {
+		m = &Loggers{Trace: Trace, Info: Info, Warning: Waring, Error: Error}
+	}
+	return &Handler{loggers: m}
+}
+
+func (h *Handler) logger(lvl slog.Level) *Logger {
+	switch {
+	case lvl < slog.LevelInfo:
+		return h.loggers.Trace
+	case lvl < slog.LevelWarn:
+		return h.loggers.Info
+	case lvl < slog.LevelError:
+		return h.loggers.Warning
+	default:
+		return h.loggers.Error
+	}
+}
+
+func (h *Handler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return h.logger(lvl).mayEnable(ctx)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	l := h.logger(r.Level)
+	if !l.enabledAt(ctx, 0, r.PC) {
+		return nil
+	}
+	e := Entry{Time: r.Time, Level: l.level, Logger: l.name, Message: r.Message}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		e.File, e.Line = frame.File, frame.Line
+	} else {
+		e.File = "???"
+	}
+	e.Fields = make([]Field, len(h.fields), len(h.fields)+r.NumAttrs())
+	copy(e.Fields, h.fields)
+	r.Attrs(func(a slog.Attr) bool {
+		e.Fields = appendAttr(e.Fields, h.group, a)
+		return true
+	})
+	stampRequestID(ctx, &e)
+	return l.WithContext(ctx).write(&e)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]Field, len(h.fields), len(h.fields)+len(attrs))
+	copy(fields, h.fields)
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.group, a)
+	}
+	return &Handler{loggers: h.loggers, fields: fields, group: h.group}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{loggers: h.loggers, fields: h.fields, group: h.group + name + "."}
+}
+
+func appendAttr(fields []Field, group string, a slog.Attr) []Field {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			fields = appendAttr(fields, group, ga)
+		}
+		return fields
+	}
+	if a.Key == "" {
+		return fields
+	}
+	return append(fields, Field{Key: group + a.Key, Value: v.Any()})
+}
@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const suppressReportInterval = 10 * time.Second
+
+type throttle struct {
+	mu          sync.Mutex
+	keep, every uint64
+	counter     uint64
+	limit       int
+	per         time.Duration
+	window      time.Time
+	count       int
+	suppressed  int64
+	lastReport  time.Time
+	notify      func()
+}
+
+func (t *throttle) allow(now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.every > 0 {
+		n := t.counter % t.every
+		t.counter++
+		if n >= t.keep {
+			return t.suppress()
+		}
+	}
+	if t.limit > 0 {
+		if now.Sub(t.window) >= t.per {
+			t.window, t.count = now, 0
+		}
+		if t.count >= t.limit {
+			return t.suppress()
+		}
+		t.count++
+	}
+	return true
+}
+
+func (t *throttle) suppress() bool {
+	if t.suppressed++; t.suppressed == 1 && t.notify != nil {
+		t.notify()
+	}
+	return false
+}
+
+func (t *throttle) report() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := t.suppressed
+	t.suppressed = 0
+	return n
+}
+
+func parseSample(s string) (keep, every uint64, err error) {
+	parts := strings.SplitN(strings.TrimSpace(s), "/", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid sample %q", s)
+	}
+	if keep, err = strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if every, err = strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if every == 0 || keep > every {
+		return 0, 0, fmt.Errorf("invalid sample %q", s)
+	}
+	return keep, every, nil
+}
+
+func parseRateLimit(s string) (int, time.Duration, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), "/", 2)
+	limit, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || limit < 0 {
+		return 0, 0, fmt.Errorf("invalid ratelimit %q", s)
+	}
+	per := time.Second
+	if len(parts) == 2 {
+		switch unit := strings.ToLower(strings.TrimSpace(parts[1])); unit {
+		case "s", "sec", "second":
+		case "m", "min", "minute":
+			per = time.Minute
+		case "h", "hour":
+			per = time.Hour
+		default:
+			if per, err = time.ParseDuration(unit); err != nil || per <= 0 {
+				return 0, 0, fmt.Errorf("invalid ratelimit %q", s)
+			}
+		}
+	}
+	return limit, per, nil
+}
+
+func (l *Logger) SetSampling(keep, every uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t := l.throttleLocked()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.keep, t.every, t.counter = keep, every, 0
+}
+
+func (l *Logger) SetRateLimit(limit int, per time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t := l.throttleLocked()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limit, t.per, t.count = limit, per, 0
+}
+
+func (l *Logger) throttleLocked() *throttle {
+	if l.throttle == nil {
+		l.throttle = &throttle{notify: func() { noteSuppressed(l) }}
+	}
+	return l.throttle
+}
+
+// Suppression counts are reported by one shared goroutine that only runs
+// while some logger has suppressed messages, so loggers replaced by
+// GetLogger or a preset leave nothing running behind them.
+var (
+	suppressMu      sync.Mutex
+	suppressPending = map[*Logger]bool{}
+	suppressRunning bool
+)
+
+func noteSuppressed(l *Logger) {
+	suppressMu.Lock()
+	defer suppressMu.Unlock()
+	suppressPending[l] = true
+	if !suppressRunning {
+		suppressRunning = true
+		go reportSuppressed()
+	}
+}
+
+func reportSuppressed() {
+	ticker := time.NewTicker(suppressReportInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		suppressMu.Lock()
+		pending := suppressPending
+		suppressPending = map[*Logger]bool{}
+		if len(pending) == 0 {
+			suppressRunning = false
+			suppressMu.Unlock()
+			return
+		}
+		suppressMu.Unlock()
+		for l := range pending {
+			l.reportSuppressed(now)
+		}
+	}
+}
+
+func (l *Logger) reportSuppressed(now time.Time) {
+	l.mu.Lock()
+	t := l.throttle
+	l.mu.Unlock()
+	if n := t.report(); n > 0 {
+		_, _ = l.writeSinks(&Entry{Time: now, Level: l.level, Logger: l.name, File: "???",
+			Message: fmt.Sprintf("%d messages suppressed by sampling/rate limit", n)})
+	}
+}
+
+func (l *Logger) throttled(e *Entry) bool {
+	l.mu.Lock()
+	t := l.throttle
+	l.mu.Unlock()
+	return t != nil && !t.allow(e.Time)
+}
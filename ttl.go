@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const TTLField = "ttl"
+
+func (l *Logger) SetTTL(ttl string) error {
+	if ttl != "" {
+		if _, err := parseTTL(ttl); err != nil {
+			return err
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ttl = ttl
+	return nil
+}
+
+func (m *Loggers) SetTTL(ttl string) error {
+	if _, err := parseTTL(ttl); ttl != "" && err != nil {
+		return err
+	}
+	m.each(func(l *Logger) { _ = l.SetTTL(ttl) })
+	return nil
+}
+
+func TTLOf(e Entry) (time.Duration, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == TTLField {
+			if s, OK := e.Fields[i].Value.(string); OK {
+				if d, err := parseTTL(s); err == nil {
+					return d, true
+				}
+			}
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+func parseTTL(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit == 0 {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return 0, fmt.Errorf("invalid ttl %q", s)
+		}
+		return d, nil
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid ttl %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+func (l *Logger) stampTTL(e *Entry) {
+	l.mu.Lock()
+	ttl := l.ttl
+	l.mu.Unlock()
+	if ttl != "" {
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], Field{Key: TTLField, Value: ttl})
+	}
+}
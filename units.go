@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Units int
+
+const (
+	UnitsDefault Units = iota
+	UnitsRaw
+	UnitsHuman
+	UnitsBoth
+)
+
+func parseUnits(s string) (Units, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "default", "":
+		return UnitsDefault, true
+	case "raw":
+		return UnitsRaw, true
+	case "human":
+		return UnitsHuman, true
+	case "both":
+		return UnitsBoth, true
+	}
+	return UnitsDefault, false
+}
+
+type humanValue interface {
+	raw() interface{}
+	human() string
+}
+
+type bytesValue int64
+
+func (b bytesValue) raw() interface{} {
+	return int64(b)
+}
+
+func (b bytesValue) human() string {
+	return humanBytes(int64(b))
+}
+
+type durValue time.Duration
+
+func (d durValue) raw() interface{} {
+	return time.Duration(d).Nanoseconds()
+}
+
+func (d durValue) human() string {
+	return time.Duration(d).String()
+}
+
+func Bytes(key string, n int64) Field {
+	return Field{Key: key, Value: bytesValue(n)}
+}
+
+func Dur(key string, d time.Duration) Field {
+	return Field{Key: key, Value: durValue(d)}
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < unit {
+		return strconv.FormatInt(n, 10) + "B"
+	}
+	div, exp := int64(unit), 0
+	for m := abs / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return strconv.FormatFloat(float64(n)/float64(div), 'f', 1, 64) + string("KMGTPE"[exp]) + "iB"
+}
+
+func expandField(f Field, units Units, defaultUnits Units) []Field {
+	h, OK := f.Value.(humanValue)
+	if !OK {
+		return []Field{f}
+	}
+	if units == UnitsDefault {
+		units = defaultUnits
+	}
+	switch units {
+	case UnitsHuman:
+		return []Field{{Key: f.Key, Value: h.human()}}
+	case UnitsBoth:
+		return []Field{{Key: f.Key, Value: h.raw()}, {Key: f.Key + "_human", Value: h.human()}}
+	default:
+		return []Field{{Key: f.Key, Value: h.raw()}}
+	}
+}
@@ -0,0 +1,418 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const compressSuffix = ".gz"
+
+type WriterOption func(*RotatingWriter)
+
+func Reserve(day int) WriterOption {
+	return func(l *RotatingWriter) {
+		l.reserve = day
+	}
+}
+
+func Compress(compressed bool) WriterOption {
+	return func(l *RotatingWriter) {
+		l.compressed = compressed
+	}
+}
+
+func TimeFormat(format string) WriterOption {
+	return func(l *RotatingWriter) {
+		l.timeFormat = format
+	}
+}
+
+func MaxSize(size int64) WriterOption {
+	return func(l *RotatingWriter) {
+		l.maxSize = size
+	}
+}
+
+func WithClock(c Clock) WriterOption {
+	return func(l *RotatingWriter) {
+		l.clock = c
+	}
+}
+
+func Retention(policy RetentionPolicy) WriterOption {
+	return func(l *RotatingWriter) {
+		l.policy = policy
+	}
+}
+
+func UTC(utc bool) WriterOption {
+	return func(l *RotatingWriter) {
+		l.utc = utc
+	}
+}
+
+const (
+	SharedOff  = "false"
+	SharedOn   = "true"
+	SharedAuto = "auto"
+)
+
+// Shared tags file names with identity values such as the hostname. Retention
+// only sees files carrying the current tag, so avoid per-run values like pid
+// unless old files are cleaned up elsewhere.
+func Shared(mode string, tags []string) WriterOption {
+	return func(l *RotatingWriter) {
+		l.shared = mode
+		l.sharedTags = tags
+	}
+}
+
+func MaxBackups(n int) WriterOption {
+	return func(l *RotatingWriter) {
+		l.maxBackups = n
+	}
+}
+
+func MaxTotalSize(size int64) WriterOption {
+	return func(l *RotatingWriter) {
+		l.maxTotalSize = size
+	}
+}
+
+func NewRotatingWriter(logPath string, options ...WriterOption) (*RotatingWriter, error) {
+	l := newRotatingWriter(logPath, options...)
+	return l, checkWritableDir(l.dir)
+}
+
+func newRotatingWriter(logPath string, options ...WriterOption) *RotatingWriter {
+	dir, name := filepath.Split(logPath)
+	if dir == "" {
+		dir = "."
+	}
+	ext := filepath.Ext(name)
+	l := &RotatingWriter{
+		dir:          dir,
+		name:         strings.TrimSuffix(name, ext) + ".",
+		ext:          ext,
+		linkFileName: logPath,
+		timeFormat:   defaultTimeFormat,
+		compressed:   defaultCompress,
+		shared:       defaultShared,
+	}
+	for _, o := range options {
+		o(l)
+	}
+	if l.shared == SharedOn || l.shared == SharedAuto && isSharedDir(nearestDir(dir)) {
+		if tag := identityTag(l.sharedTags); tag != "" {
+			l.name += tag + "."
+			l.linkFileName = filepath.Join(dir, l.name[:len(l.name)-1]+ext)
+		}
+	}
+	return l
+}
+
+func nearestDir(dir string) string {
+	if p, err := existingAncestor(dir); err == nil {
+		return p
+	}
+	return dir
+}
+
+type RotatingWriter struct {
+	mu                     sync.Mutex
+	dir, name, ext, suffix string
+	linkFileName           string
+	file                   *os.File
+	index                  int
+	size                   int64
+	scanned                bool
+
+	reserve      int
+	maxBackups   int
+	maxTotalSize int64
+	shared       string
+	sharedTags   []string
+	compressed   bool
+	utc          bool
+	timeFormat   string
+	maxSize      int64
+	clock        Clock
+	policy       RetentionPolicy
+}
+
+func (l *RotatingWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := l.openOrNew(len(p))
+	if err != nil {
+		reportError("write fail, msg(%s)", err)
+		return 0, err
+	}
+	n, err := f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+func (l *RotatingWriter) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Sync()
+}
+
+func (l *RotatingWriter) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	defer func() { l.file = nil }()
+	return l.file.Close()
+}
+
+func (l *RotatingWriter) deleteFile(suffix, current string) {
+	policy := l.retentionPolicy()
+	if policy == nil {
+		return
+	}
+	now, _ := time.Parse(l.timeFormat, suffix)
+	files, err := l.Files()
+	if err != nil && !os.IsNotExist(err) {
+		reportError("open log dir %s failed: %s", l.dir, err)
+		return
+	}
+	inventory := files[:0]
+	for _, f := range files {
+		if _, busy := compressing.Load(f.Path); !busy {
+			f.Current = f.Current || f.Path == current
+			inventory = append(inventory, f)
+		}
+	}
+	for _, d := range policy.Apply(now, inventory) {
+		if d.File.Current {
+			continue
+		}
+		switch d.Action {
+		case RetentionDelete:
+			recordRetention(d, d.File.Size, l.remove(d.File.Path))
+		case RetentionCompress:
+			if !d.File.Compressed {
+				d := d
+				enqueueCompress(d.File.Path, func() {
+					fi, err := os.Stat(d.File.Path + compressSuffix)
+					freed := int64(0)
+					if err == nil {
+						freed = d.File.Size - fi.Size()
+					}
+					recordRetention(d, freed, err)
+					scheduleRetention(l)
+				})
+			}
+		case RetentionMove:
+			err := os.MkdirAll(filepath.Dir(d.Target), os.ModeDir|0744)
+			if err == nil {
+				err = os.Rename(d.File.Path, d.Target)
+			}
+			if err != nil {
+				reportError("move file %s failed: %s", d.File.Path, err)
+			}
+			recordRetention(d, d.File.Size, err)
+		}
+	}
+}
+
+func (l *RotatingWriter) retentionPolicy() RetentionPolicy {
+	if l.policy != nil {
+		return l.policy
+	}
+	var policies []RetentionPolicy
+	if l.reserve > 0 {
+		policies = append(policies, AgePolicy(time.Duration(l.reserve)*24*time.Hour))
+	}
+	if l.maxBackups > 0 {
+		policies = append(policies, CountPolicy(l.maxBackups))
+	}
+	if l.maxTotalSize > 0 {
+		policies = append(policies, SizePolicy(l.maxTotalSize))
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	return AnyOf(policies...)
+}
+
+func (l *RotatingWriter) remove(path string) error {
+	err := os.Remove(path)
+	if err != nil {
+		reportError("remove file %s failed", path)
+	}
+	return err
+}
+
+func (l *RotatingWriter) parseName(filename string) (time.Time, int, error) {
+	nameNoPrefix := strings.TrimPrefix(filename, l.name)
+	if filename == nameNoPrefix {
+		return time.Time{}, 0, errors.New("mismatched prefix")
+	}
+	nameNoSuffix := strings.TrimSuffix(nameNoPrefix, compressSuffix)
+	nameNoSuffix = strings.TrimSuffix(nameNoSuffix, l.ext)
+	if nameNoPrefix == nameNoSuffix {
+		return time.Time{}, 0, errors.New("mismatched extension")
+	}
+	t, err := time.Parse(l.timeFormat, nameNoSuffix)
+	if err == nil {
+		return t, 0, nil
+	}
+	if i := strings.LastIndexByte(nameNoSuffix, '.'); i > 0 {
+		if index, e := strconv.Atoi(nameNoSuffix[i+1:]); e == nil && index > 0 {
+			if t, e = time.Parse(l.timeFormat, nameNoSuffix[:i]); e == nil {
+				return t, index, nil
+			}
+		}
+	}
+	return time.Time{}, 0, err
+}
+
+func (l *RotatingWriter) lastIndex(suffix string) int {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return 0
+	}
+	last := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if t, index, e := l.parseName(entry.Name()); e == nil && index > last && t.Format(l.timeFormat) == suffix {
+			last = index
+		}
+	}
+	return last
+}
+
+func (l *RotatingWriter) exceeded(n int) bool {
+	return l.maxSize > 0 && l.size > 0 && l.size+int64(n) > l.maxSize
+}
+
+func (l *RotatingWriter) openOrNew(n int) (*os.File, error) {
+	suffix := l.timeSuffix()
+	if l.file != nil && l.suffix == suffix && !l.exceeded(n) {
+		return l.file, nil
+	}
+	if l.file == nil {
+		if err := os.MkdirAll(l.dir, os.ModeDir|0744); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if !l.scanned && l.file != nil {
+				l.scanned = true
+				current := l.file.Name()
+				compressWG.Add(1)
+				go func() {
+					defer compressWG.Done()
+					l.scanLeftovers(current)
+				}()
+			}
+		}()
+	}
+	index := 0
+	if l.file != nil && l.suffix == suffix {
+		index = l.index + 1
+	} else if l.file == nil {
+		index = l.lastIndex(suffix)
+		filename := l.fileName(suffix, index)
+		if fi, err := os.Stat(filename); err == nil {
+			l.size = fi.Size()
+			if !l.exceeded(n) {
+				if f, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND, 0644); err == nil {
+					l.file, l.suffix, l.index = f, suffix, index
+					return f, nil
+				}
+			}
+			index++
+		} else if _, err = os.Stat(filename + compressSuffix); err == nil {
+			index++
+		}
+	}
+	return l.rotate(suffix, index)
+}
+
+func (l *RotatingWriter) rotate(suffix string, index int) (*os.File, error) {
+	filename := l.fileName(suffix, index)
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		if l.file == nil {
+			return nil, fmt.Errorf("can't open new logfile: %s", err)
+		}
+		reportError("can't open new logfile: %s", err)
+		return l.file, nil
+	}
+	if l.file != nil {
+		old := l.file.Name()
+		_ = l.file.Close()
+		if l.compressed {
+			enqueueCompress(old, func() { scheduleRetention(l) })
+		}
+	}
+	l.file, l.suffix, l.index, l.size = f, suffix, index, 0
+	scheduleRetention(l)
+	if err = os.Remove(l.linkFileName); err == nil || os.IsNotExist(err) {
+		err = os.Link(filename, l.linkFileName)
+	}
+	if err != nil {
+		reportError("rotate log file error: %s", err)
+	}
+	return f, nil
+}
+
+// scanLeftovers runs once per writer, in the background and without l.mu,
+// so a directory full of old files never stalls the first Write.
+func (l *RotatingWriter) scanLeftovers(current string) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(l.dir, name)
+		if entry.IsDir() || !strings.HasPrefix(name, l.name) || path == current {
+			continue
+		}
+		if src := strings.TrimSuffix(path, compressSuffix+tmpSuffix); src != path {
+			if _, busy := compressing.Load(src); !busy {
+				_ = os.Remove(path)
+			}
+			continue
+		}
+		if _, _, e := l.parseName(name); e == nil && l.compressed && !strings.HasSuffix(name, compressSuffix) {
+			enqueueCompress(path, func() { scheduleRetention(l) })
+		}
+	}
+	scheduleRetention(l)
+}
+
+func (l *RotatingWriter) fileName(suffix string, index int) string {
+	if index > 0 {
+		return filepath.Join(l.dir, fmt.Sprintf("%s%s.%d%s", l.name, suffix, index, l.ext))
+	}
+	return filepath.Join(l.dir, fmt.Sprintf("%s%s%s", l.name, suffix, l.ext))
+}
+
+func (l *RotatingWriter) timeSuffix() string {
+	t := rotationNow()
+	if l.clock != nil {
+		t = l.clock.Now()
+	}
+	if l.utc {
+		return t.UTC().Format(l.timeFormat)
+	}
+	return t.Format(l.timeFormat)
+}
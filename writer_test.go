@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseName(t *testing.T) {
+	w := newRotatingWriter(filepath.Join(t.TempDir(), "app.log"))
+	cases := []struct {
+		name  string
+		index int
+		OK    bool
+	}{
+		{"app.20240102.log", 0, true},
+		{"app.20240102.3.log", 3, true},
+		{"app.20240102.12.log.gz", 12, true},
+		{"app.20240102.log.gz", 0, true},
+		{"other.20240102.log", 0, false},
+		{"app.20240102.txt", 0, false},
+		{"app.2024x102.log", 0, false},
+		{"app.20240102.0.log", 0, false},
+		{"app.20240102.-1.log", 0, false},
+		{"app.20240102.x.log", 0, false},
+	}
+	for _, c := range cases {
+		p, index, err := w.parseName(c.name)
+		if (err == nil) != c.OK || index != c.index {
+			t.Errorf("parseName(%q) = %d, %v; want %d, ok=%v", c.name, index, err, c.index, c.OK)
+			continue
+		}
+		if c.OK && p.Format(w.timeFormat) != "20240102" {
+			t.Errorf("parseName(%q) period = %s", c.name, p)
+		}
+	}
+}
+
+func TestLastIndex(t *testing.T) {
+	dir := t.TempDir()
+	w := newRotatingWriter(filepath.Join(dir, "app.log"))
+	if n := w.lastIndex("20240102"); n != 0 {
+		t.Fatalf("lastIndex on an empty dir = %d, want 0", n)
+	}
+	for _, name := range []string{
+		"app.20240102.log",
+		"app.20240102.2.log",
+		"app.20240102.10.log.gz",
+		"app.20240103.20.log",
+		"other.20240102.30.log",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "app.20240102.40.log"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if n := w.lastIndex("20240102"); n != 10 {
+		t.Fatalf("lastIndex = %d, want 10", n)
+	}
+}
+
+func TestScanLeftoversRemovesStaleTemp(t *testing.T) {
+	dir := t.TempDir()
+	w := newRotatingWriter(filepath.Join(dir, "app.log"))
+	stale := filepath.Join(dir, "app.20240101.log.gz.tmp")
+	busy := filepath.Join(dir, "app.20240102.log.gz.tmp")
+	other := filepath.Join(dir, "other.20240101.log.gz.tmp")
+	for _, path := range []string{stale, busy, other} {
+		if err := os.WriteFile(path, []byte("partial"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src := filepath.Join(dir, "app.20240102.log")
+	compressing.Store(src, true)
+	defer compressing.Delete(src)
+	w.scanLeftovers(filepath.Join(dir, "app.20240103.log"))
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale temp file was kept: %v", err)
+	}
+	for _, path := range []string{busy, other} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s was removed: %v", filepath.Base(path), err)
+		}
+	}
+}